@@ -6,16 +6,23 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
+	// Встроенная база часовых поясов для задач с указанным часовым поясом.
+	_ "time/tzdata"
 
 	"github.com/Memonagi/go_final_project/internal/database"
 	"github.com/Memonagi/go_final_project/internal/handler"
 	"github.com/Memonagi/go_final_project/internal/service"
+	"github.com/Memonagi/go_final_project/pkg/date"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	defaultPort   = 7540
 	defaultDBName = "scheduler.db"
+	// defaultTrashDays срок хранения задач в корзине в днях.
+	defaultTrashDays = 30
+	purgeInterval    = time.Hour
 )
 
 func main() {
@@ -34,6 +41,12 @@ func main() {
 		dbFile = defaultDBName
 	}
 
+	if holidaysFile := os.Getenv("TODO_HOLIDAYS"); holidaysFile != "" {
+		if err := date.LoadHolidays(holidaysFile); err != nil {
+			logrus.Panicf("ошибка загрузки календаря праздников: %v", err)
+		}
+	}
+
 	db, err := database.NewDB(ctx, dbFile)
 	if err != nil {
 		logrus.Panicf("ошибка подключения к БД: %v", err)
@@ -47,6 +60,14 @@ func main() {
 
 	svc := service.New(db)
 
+	trashDays, _ := strconv.Atoi(os.Getenv("TODO_TRASH_DAYS"))
+
+	if trashDays <= 0 {
+		trashDays = defaultTrashDays
+	}
+
+	go svc.RunPurge(ctx, time.Duration(trashDays)*24*time.Hour, purgeInterval)
+
 	server := handler.New(port, svc)
 
 	if err := server.Run(ctx); err != nil {
@@ -3,19 +3,172 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Memonagi/go_final_project/internal/models"
-	// Импорт для работы с БД.
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
-const limit = 50
+const (
+	limit = 50
+	// driverName драйвер SQLite с дополнительными функциями.
+	driverName = "sqlite3_scheduler"
+)
+
+var errNotFound = errors.New("задача не найдена")
+
+// likeEscaper экранирует спецсимволы шаблона LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+//nolint:gochecknoinits
+func init() {
+	// Встроенная функция lower в SQLite меняет регистр только латинских букв.
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("unicode_lower", strings.ToLower, true)
+		},
+	})
+}
 
 type DB struct {
 	db *sql.DB
+	// fts поиск выполняется по полнотекстовому индексу.
+	fts bool
+}
+
+// migrations изменения схемы БД, применяемые по порядку. Номер последней
+// примененной миграции хранится в PRAGMA user_version.
+// Дополнительные поля задач хранятся в отдельных таблицах, чтобы таблица scheduler
+// сохраняла исходную структуру из пяти столбцов.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS scheduler (
+        id       INTEGER PRIMARY KEY AUTOINCREMENT,
+        date     CHAR(8)      NOT NULL,
+        title    VARCHAR(128) NOT NULL,
+        comment  TEXT,
+        repeat   VARCHAR(128)  NOT NULL
+    );`,
+	`CREATE TABLE task_details (
+        task_id  INTEGER PRIMARY KEY REFERENCES scheduler (id) ON DELETE CASCADE,
+        time     CHAR(5)     NOT NULL DEFAULT '',
+        timezone VARCHAR(64) NOT NULL DEFAULT ''
+    );`,
+	`ALTER TABLE task_details ADD COLUMN until CHAR(8) NOT NULL DEFAULT '';
+    ALTER TABLE task_details ADD COLUMN count INTEGER;`,
+	`CREATE TABLE exceptions (
+        task_id  INTEGER NOT NULL REFERENCES scheduler (id) ON DELETE CASCADE,
+        date     CHAR(8) NOT NULL,
+        PRIMARY KEY (task_id, date)
+    );`,
+	`ALTER TABLE task_details ADD COLUMN anchor VARCHAR(16) NOT NULL DEFAULT '';`,
+	// Выполнения задач с нормой выполнений: по ним считается число выполнений в текущем периоде.
+	`CREATE TABLE completions (
+        id       INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id  INTEGER NOT NULL,
+        date     CHAR(8) NOT NULL
+    );
+    CREATE INDEX completions_task_id ON completions (task_id);`,
+	// История выполнений не связана внешним ключом со scheduler и сохраняется после удаления задачи.
+	`ALTER TABLE completions ADD COLUMN time CHAR(5) NOT NULL DEFAULT '';
+    ALTER TABLE completions ADD COLUMN done_at VARCHAR(32) NOT NULL DEFAULT '';
+    ALTER TABLE completions ADD COLUMN note TEXT NOT NULL DEFAULT '';`,
+	// Удаленные и выполненные разовые задачи попадают в корзину и окончательно удаляются после срока хранения.
+	`CREATE TABLE trash (
+        task_id    INTEGER PRIMARY KEY REFERENCES scheduler (id) ON DELETE CASCADE,
+        deleted_at VARCHAR(32) NOT NULL,
+        reason     VARCHAR(16) NOT NULL
+    );`,
+	`ALTER TABLE task_details ADD COLUMN priority INTEGER;`,
+	// Метки задач: название метки хранится один раз и связывается с задачами.
+	`CREATE TABLE tags (
+        id       INTEGER PRIMARY KEY AUTOINCREMENT,
+        name     VARCHAR(32) NOT NULL UNIQUE
+    );
+    CREATE TABLE task_tags (
+        task_id  INTEGER NOT NULL REFERENCES scheduler (id) ON DELETE CASCADE,
+        tag_id   INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+        PRIMARY KEY (task_id, tag_id)
+    );
+    CREATE INDEX task_tags_tag_id ON task_tags (tag_id);`,
+	// Проекты: задача входит не более чем в один проект, при удалении проекта попадает во входящие.
+	`CREATE TABLE projects (
+        id       INTEGER PRIMARY KEY AUTOINCREMENT,
+        name     VARCHAR(64) NOT NULL UNIQUE
+    );
+    ALTER TABLE task_details ADD COLUMN project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL;
+    CREATE INDEX task_details_project_id ON task_details (project_id);`,
+}
+
+// taskPriority приоритет задачи; задача без приоритета считается задачей с низшим приоритетом (4).
+const taskPriority = "COALESCE(d.priority, 4)"
+
+// taskOrder порядок списка задач: по дате и времени, задачи на одно время — по приоритету.
+const taskOrder = "s.date, d.time, " + taskPriority
+
+// notTrashed условие, исключающее задачи из корзины; добавляется ко всем запросам задач.
+const notTrashed = "NOT EXISTS (SELECT 1 FROM trash t WHERE t.task_id = s.id)"
+
+// taskColumns столбцы задачи вместе с дополнительными полями.
+const taskColumns = `s.id, s.date, s.title, s.comment, s.repeat,
+        COALESCE(d.time, ''), COALESCE(d.timezone, ''), COALESCE(d.until, ''), COALESCE(d.count, ''),
+        COALESCE(d.anchor, ''), COALESCE(d.priority, ''),
+        COALESCE(d.project_id, ''), ` + taskTags
+
+// selectTask запрос задач вместе с дополнительными полями.
+const selectTask = `SELECT ` + taskColumns + `
+    FROM scheduler s LEFT JOIN task_details d ON d.task_id = s.id`
+
+// upsertDetails запрос сохранения дополнительных полей задачи.
+const upsertDetails = `INSERT INTO task_details (task_id, time, timezone, until, count, anchor, priority, project_id)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (task_id) DO UPDATE SET time = excluded.time, timezone = excluded.timezone,
+        until = excluded.until, count = excluded.count, anchor = excluded.anchor, priority = excluded.priority,
+        project_id = excluded.project_id`
+
+// scanner общий интерфейс sql.Row и sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask считывает задачу, полученную запросом selectTask; extra — следующие за задачей столбцы.
+func scanTask(row scanner, task *models.Task, extra ...any) error {
+	var tags string
+
+	err := row.Scan(append([]any{&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat,
+		&task.Time, &task.Timezone, &task.Until, &task.Count, &task.Anchor, &task.Priority,
+		&task.ProjectID, &tags},
+		extra...)...)
+	if err != nil {
+		return err
+	}
+
+	task.Tags = splitTags(tags)
+
+	return nil
+}
+
+// detailsArgs возвращает параметры запроса upsertDetails.
+func detailsArgs(id any, task models.Task) []any {
+	var count, priority, project any
+
+	if task.Count != "" {
+		count = task.Count
+	}
+
+	if task.Priority != "" {
+		priority = task.Priority
+	}
+
+	if task.ProjectID != "" {
+		project = task.ProjectID
+	}
+
+	return []any{id, task.Time, task.Timezone, task.Until, count, task.Anchor, priority, project}
 }
 
 // NewDB подключает к БД.
@@ -33,25 +186,55 @@ func NewDB(ctx context.Context, dbFile string) (*DB, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite3", dbFile)
+	db, err := sql.Open(driverName, dbFile+"?_foreign_keys=on")
 	if err != nil {
 		return nil, fmt.Errorf("ошибка открытия файла БД: %w", err)
 	}
 
-	createTable := `CREATE TABLE IF NOT EXISTS scheduler (
-        id       INTEGER PRIMARY KEY AUTOINCREMENT,
-        date     CHAR(8)      NOT NULL,
-        title    VARCHAR(128) NOT NULL,
-        comment  TEXT,
-        repeat   VARCHAR(128)  NOT NULL
-    );`
+	if err = migrate(ctx, db); err != nil {
+		return nil, err
+	}
 
-	_, err = db.ExecContext(ctx, createTable)
+	fts, err := setupSearch(ctx, db)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка создания таблицы: %w", err)
+		return nil, err
 	}
 
-	return &DB{db}, nil
+	return &DB{db: db, fts: fts}, nil
+}
+
+// migrate применяет к БД еще не примененные миграции.
+func migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("ошибка получения версии схемы БД: %w", err)
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("ошибка начала транзакции: %w", err)
+		}
+
+		if _, err = tx.ExecContext(ctx, migrations[version]); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("ошибка создания таблицы: %w", err)
+		}
+
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("ошибка обновления версии схемы БД: %w", err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("ошибка применения миграции: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // CloseDatabase закрывает БД.
@@ -65,9 +248,18 @@ func (db *DB) CloseDatabase() error {
 
 // AddTask добавляет задачу в БД.
 func (db *DB) AddTask(ctx context.Context, task models.Task) (string, error) {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
 	query := "INSERT INTO scheduler (date, title, comment, repeat) VALUES (?, ?, ?, ?)"
 
-	res, err := db.db.ExecContext(ctx, query, task.Date, task.Title, task.Comment, task.Repeat)
+	res, err := tx.ExecContext(ctx, query, task.Date, task.Title, task.Comment, task.Repeat)
 	if err != nil {
 		return "", fmt.Errorf("ошибка добавления задачи в БД: %w", err)
 	}
@@ -77,28 +269,122 @@ func (db *DB) AddTask(ctx context.Context, task models.Task) (string, error) {
 		return "", fmt.Errorf("ошибка получения ID добавленной задачи: %w", err)
 	}
 
+	if _, err = tx.ExecContext(ctx, upsertDetails, detailsArgs(id, task)...); err != nil {
+		return "", fmt.Errorf("ошибка добавления задачи в БД: %w", err)
+	}
+
+	if err = setTags(ctx, tx, id, task.Tags); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("ошибка добавления задачи в БД: %w", err)
+	}
+
 	return strconv.Itoa(int(id)), nil
 }
 
-// GetAllTasks получает все задачи из БД.
-func (db *DB) GetAllTasks(ctx context.Context) ([]models.Task, error) {
-	rows, err := db.db.QueryContext(ctx, "SELECT * FROM scheduler ORDER BY date LIMIT ?", limit)
+// GetAllTasks получает из БД задачи, подходящие под условия отбора. Текст ищется по полнотекстовому
+// индексу: сначала слова ищутся по началу как есть, а если так ничего не найдено — без окончаний.
+// Без FTS5 текст ищется как подстрока заголовка или комментария без учета регистра.
+func (db *DB) GetAllTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	var (
+		where = []string{notTrashed}
+		args  []any
+	)
+
+	if filter.Date != "" {
+		where = append(where, "s.date = ?")
+		args = append(args, filter.Date)
+	}
+
+	if len(filter.Priorities) > 0 {
+		where = append(where, taskPriority+" IN ("+placeholders(len(filter.Priorities))+")")
+
+		for _, e := range filter.Priorities {
+			args = append(args, e)
+		}
+	}
+
+	switch filter.Project {
+	case "":
+	case models.ProjectInbox:
+		where = append(where, "d.project_id IS NULL")
+	default:
+		where = append(where, "d.project_id = ?")
+		args = append(args, filter.Project)
+	}
+
+	tagWhere, tagArgs := tagConditions(filter.Tags, filter.ExcludedTags)
+	where, args = append(where, tagWhere...), append(args, tagArgs...)
+
+	if filter.Search == "" {
+		return db.queryTasks(ctx, selectTask, where, args, taskOrder, false)
+	}
+
+	if !db.fts {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Search)) + "%"
+		where = append(where, `(unicode_lower(s.title) LIKE ? ESCAPE '\' OR unicode_lower(s.comment) LIKE ? ESCAPE '\')`)
+
+		return db.queryTasks(ctx, selectTask, where, append(args, pattern, pattern), taskOrder, false)
+	}
+
+	var previous string
+
+	for _, stemmed := range []bool{false, true} {
+		match := ftsQuery(filter.Search, stemmed)
+		if match == "" || match == previous {
+			break
+		}
+
+		previous = match
+
+		tasks, err := db.queryTasks(ctx, selectSearch, append(where, "scheduler_fts MATCH ?"), append(args, match),
+			searchOrder, true)
+		if err != nil || len(tasks) > 0 {
+			return tasks, err
+		}
+	}
+
+	return []models.Task{}, nil
+}
+
+// queryTasks выполняет запрос списка задач с условиями where; для поиска по индексу (fts) считываются
+// также выделенные совпадения.
+func (db *DB) queryTasks(ctx context.Context, query string, where []string, args []any, order string,
+	fts bool,
+) ([]models.Task, error) {
+	query += " WHERE " + strings.Join(where, " AND ")
+
+	rows, err := db.db.QueryContext(ctx, query+" ORDER BY "+order+" LIMIT ?", append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка поиска задач в БД: %w", err)
 	}
 
 	defer rows.Close()
 
-	var tasks []models.Task
+	tasks := []models.Task{}
 
 	for rows.Next() {
-		var taskStruct models.Task
+		var (
+			taskStruct models.Task
+			extra      []any
+		)
 
-		err = rows.Scan(&taskStruct.ID, &taskStruct.Date, &taskStruct.Title, &taskStruct.Comment, &taskStruct.Repeat)
+		if fts {
+			extra = []any{&taskStruct.Highlight, &taskStruct.Snippet}
+		}
+
+		err = scanTask(rows, &taskStruct, extra...)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка получения списка задач из БД: %w", err)
 		}
 
+		// Фрагмент комментария нужен, только если совпадение есть в самом комментарии.
+		if !strings.Contains(taskStruct.Snippet, markOpen) {
+			taskStruct.Snippet = ""
+		}
+
 		tasks = append(tasks, taskStruct)
 	}
 
@@ -106,18 +392,14 @@ func (db *DB) GetAllTasks(ctx context.Context) ([]models.Task, error) {
 		return nil, fmt.Errorf("ошибка получения списка задач из БД: %w", err)
 	}
 
-	if len(tasks) == 0 {
-		return []models.Task{}, nil
-	}
-
 	return tasks, nil
 }
 
 // GetTaskID получает задачу из БД по ее ID.
 func (db *DB) GetTaskID(ctx context.Context, id int64, task models.Task) (models.Task, error) {
-	query := "SELECT * FROM scheduler WHERE id = ?"
+	query := selectTask + " WHERE s.id = ? AND " + notTrashed
 
-	err := db.db.QueryRowContext(ctx, query, id).Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat)
+	err := scanTask(db.db.QueryRowContext(ctx, query, id), &task)
 	if err != nil {
 		return models.Task{}, fmt.Errorf("ошибка получения задачи из БД: %w", err)
 	}
@@ -127,9 +409,19 @@ func (db *DB) GetTaskID(ctx context.Context, id int64, task models.Task) (models
 
 // UpdateTask редактирует задачу в БД.
 func (db *DB) UpdateTask(ctx context.Context, task models.Task) (models.Task, error) {
-	query := "UPDATE scheduler SET date = ?, title = ?, comment = ?, repeat = ? WHERE id = ?"
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	row, err := db.db.ExecContext(ctx, query, task.Date, task.Title, task.Comment, task.Repeat, task.ID)
+	query := "UPDATE scheduler SET date = ?, title = ?, comment = ?, repeat = ? WHERE id = ? AND " +
+		"id NOT IN (SELECT task_id FROM trash)"
+
+	row, err := tx.ExecContext(ctx, query, task.Date, task.Title, task.Comment, task.Repeat, task.ID)
 	if err != nil {
 		return models.Task{}, fmt.Errorf("ошибка обновления задачи в базе данных: %w", err)
 	}
@@ -140,22 +432,163 @@ func (db *DB) UpdateTask(ctx context.Context, task models.Task) (models.Task, er
 		return models.Task{}, fmt.Errorf("ошибка обновления задачи в базе данных: %w", err)
 	}
 
+	if _, err = tx.ExecContext(ctx, upsertDetails, detailsArgs(task.ID, task)...); err != nil {
+		return models.Task{}, fmt.Errorf("ошибка обновления задачи в базе данных: %w", err)
+	}
+
+	if err = setTags(ctx, tx, task.ID, task.Tags); err != nil {
+		return models.Task{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("ошибка обновления задачи в базе данных: %w", err)
+	}
+
 	return task, nil
 }
 
-// TaskDone выполняет задачу в БД.
-func (db *DB) TaskDone(ctx context.Context, nextDate string, id int64) error {
-	_, err := db.db.ExecContext(ctx, "UPDATE scheduler SET date = ? WHERE id = ?", nextDate, id)
+// insertCompletion запрос записи выполнения задачи в историю.
+const insertCompletion = "INSERT INTO completions (task_id, date, time, done_at, note) VALUES (?, ?, ?, ?, ?)"
+
+// TaskDone выполняет задачу в БД: переносит ее на следующую дату, сохраняет остаток повторений
+// и записывает выполнение в историю (если completion не nil).
+func (db *DB) TaskDone(ctx context.Context, task models.Task, completion *models.Completion) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, "UPDATE scheduler SET date = ?, repeat = ? WHERE id = ?", task.Date, task.Repeat, task.ID)
 	if err != nil {
 		return fmt.Errorf("ошибка выполнения задачи: %w", err)
 	}
 
+	if _, err = tx.ExecContext(ctx, upsertDetails, detailsArgs(task.ID, task)...); err != nil {
+		return fmt.Errorf("ошибка выполнения задачи: %w", err)
+	}
+
+	if completion != nil {
+		if _, err = tx.ExecContext(ctx, insertCompletion, completionArgs(*completion)...); err != nil {
+			return fmt.Errorf("ошибка записи выполнения задачи: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка выполнения задачи: %w", err)
+	}
+
 	return nil
 }
 
-// DeleteTaskID удаляет задачу из БД.
+// FinishTask переносит выполненную задачу в корзину и записывает выполнение в историю.
+func (db *DB) FinishTask(ctx context.Context, id int64, completion models.Completion) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = moveToTrash(ctx, tx, id, models.TrashDone); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, insertCompletion, completionArgs(completion)...); err != nil {
+		return fmt.Errorf("ошибка записи выполнения задачи: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка выполнения задачи: %w", err)
+	}
+
+	return nil
+}
+
+// placeholders возвращает n параметров запроса через запятую.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// completionArgs возвращает параметры запроса insertCompletion.
+func completionArgs(completion models.Completion) []any {
+	return []any{completion.TaskID, completion.Date, completion.Time, completion.DoneAt, completion.Note}
+}
+
+// CountCompletions считает выполнения задачи с датами от from включительно до to.
+func (db *DB) CountCompletions(ctx context.Context, id int64, from string, to string) (int, error) {
+	var count int
+
+	err := db.db.QueryRowContext(ctx, "SELECT count(id) FROM completions WHERE task_id = ? AND date >= ? AND date < ?",
+		id, from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения числа выполнений из БД: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetCompletions получает из БД историю выполнений задачи по порядку, в том числе для задач из корзины.
+func (db *DB) GetCompletions(ctx context.Context, id int64) ([]models.Completion, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT id, task_id, date, time, done_at, note FROM completions
+        WHERE task_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории выполнений из БД: %w", err)
+	}
+
+	defer rows.Close()
+
+	completions := []models.Completion{}
+
+	for rows.Next() {
+		var c models.Completion
+
+		if err = rows.Scan(&c.ID, &c.TaskID, &c.Date, &c.Time, &c.DoneAt, &c.Note); err != nil {
+			return nil, fmt.Errorf("ошибка получения истории выполнений из БД: %w", err)
+		}
+
+		completions = append(completions, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения истории выполнений из БД: %w", err)
+	}
+
+	return completions, nil
+}
+
+// DeleteTaskID переносит задачу в корзину.
 func (db *DB) DeleteTaskID(ctx context.Context, id int64) error {
-	row, err := db.db.ExecContext(ctx, "DELETE FROM scheduler WHERE id = ?", id)
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = moveToTrash(ctx, tx, id, models.TrashDeleted); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка удаления задачи: %w", err)
+	}
+
+	return nil
+}
+
+// moveToTrash переносит задачу в корзину с указанием причины.
+func moveToTrash(ctx context.Context, tx *sql.Tx, id int64, reason string) error {
+	row, err := tx.ExecContext(ctx, `INSERT INTO trash (task_id, deleted_at, reason)
+        SELECT s.id, ?, ? FROM scheduler s WHERE s.id = ? AND `+notTrashed,
+		time.Now().UTC().Format(time.RFC3339), reason, id)
 	if err != nil {
 		return fmt.Errorf("ошибка удаления задачи: %w", err)
 	}
@@ -163,7 +596,142 @@ func (db *DB) DeleteTaskID(ctx context.Context, id int64) error {
 	checkRow, err := row.RowsAffected()
 
 	if err != nil || checkRow == 0 {
-		return fmt.Errorf("ошибка удаления задачи: %w", err)
+		return fmt.Errorf("ошибка удаления задачи: %w", errNotFound)
+	}
+
+	return nil
+}
+
+// GetTrash получает из БД задачи из корзины, начиная с удаленных последними.
+func (db *DB) GetTrash(ctx context.Context) ([]models.Task, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT `+taskColumns+`, t.deleted_at, t.reason
+        FROM trash t JOIN scheduler s ON s.id = t.task_id LEFT JOIN task_details d ON d.task_id = s.id
+        ORDER BY t.deleted_at DESC, s.id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения корзины из БД: %w", err)
+	}
+
+	defer rows.Close()
+
+	tasks := []models.Task{}
+
+	for rows.Next() {
+		var task models.Task
+
+		if err = scanTask(rows, &task, &task.DeletedAt, &task.DeleteReason); err != nil {
+			return nil, fmt.Errorf("ошибка получения корзины из БД: %w", err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения корзины из БД: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины.
+func (db *DB) RestoreTask(ctx context.Context, id int64) error {
+	row, err := db.db.ExecContext(ctx, "DELETE FROM trash WHERE task_id = ?", id)
+	if err != nil {
+		return fmt.Errorf("ошибка восстановления задачи: %w", err)
+	}
+
+	checkRow, err := row.RowsAffected()
+
+	if err != nil || checkRow == 0 {
+		return fmt.Errorf("ошибка восстановления задачи: %w", errNotFound)
+	}
+
+	return nil
+}
+
+// PurgeTrash окончательно удаляет задачи, попавшие в корзину раньше указанного момента, и ставшие
+// ненужными метки. История выполнений при этом сохраняется.
+func (db *DB) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	row, err := tx.ExecContext(ctx, "DELETE FROM scheduler WHERE id IN (SELECT task_id FROM trash WHERE deleted_at < ?)",
+		before.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины: %w", err)
+	}
+
+	purged, err := row.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteUnusedTags); err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка очистки корзины: %w", err)
+	}
+
+	return purged, nil
+}
+
+// GetExceptions получает из БД даты, исключенные из повторений задачи.
+func (db *DB) GetExceptions(ctx context.Context, id int64) ([]string, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT date FROM exceptions
+        WHERE task_id = ? AND task_id NOT IN (SELECT task_id FROM trash) ORDER BY date`, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений из БД: %w", err)
+	}
+
+	defer rows.Close()
+
+	exceptions := []string{}
+
+	for rows.Next() {
+		var exception string
+
+		if err = rows.Scan(&exception); err != nil {
+			return nil, fmt.Errorf("ошибка получения исключений из БД: %w", err)
+		}
+
+		exceptions = append(exceptions, exception)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений из БД: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+// AddException добавляет в БД дату, исключенную из повторений задачи.
+func (db *DB) AddException(ctx context.Context, id int64, date string) error {
+	_, err := db.db.ExecContext(ctx, "INSERT OR IGNORE INTO exceptions (task_id, date) VALUES (?, ?)", id, date)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления исключения: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteException удаляет из БД дату, исключенную из повторений задачи.
+func (db *DB) DeleteException(ctx context.Context, id int64, date string) error {
+	row, err := db.db.ExecContext(ctx, "DELETE FROM exceptions WHERE task_id = ? AND date = ?", id, date)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления исключения: %w", err)
+	}
+
+	checkRow, err := row.RowsAffected()
+
+	if err != nil || checkRow == 0 {
+		return fmt.Errorf("ошибка удаления исключения: %w", errNotFound)
 	}
 
 	return nil
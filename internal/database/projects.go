@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Memonagi/go_final_project/internal/models"
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	errProjectNotFound = errors.New("проект не найден")
+	errProjectExists   = errors.New("проект с таким названием уже существует")
+)
+
+// selectProject запрос проектов с числом задач в них; задачи из корзины не учитываются.
+const selectProject = `SELECT p.id, p.name, count(s.id) FROM projects p
+    LEFT JOIN task_details d ON d.project_id = p.id
+    LEFT JOIN scheduler s ON s.id = d.task_id AND ` + notTrashed
+
+// projectError заменяет ошибку нарушения уникальности названия проекта понятной ошибкой.
+func projectError(err error) error {
+	var sqliteErr sqlite3.Error
+
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return errProjectExists
+	}
+
+	return err
+}
+
+// AddProject добавляет проект в БД.
+func (db *DB) AddProject(ctx context.Context, name string) (string, error) {
+	res, err := db.db.ExecContext(ctx, "INSERT INTO projects (name) VALUES (?)", name)
+	if err != nil {
+		return "", fmt.Errorf("ошибка добавления проекта в БД: %w", projectError(err))
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения ID добавленного проекта: %w", err)
+	}
+
+	return strconv.Itoa(int(id)), nil
+}
+
+// GetProjects получает из БД проекты по названию.
+func (db *DB) GetProjects(ctx context.Context) ([]models.Project, error) {
+	rows, err := db.db.QueryContext(ctx, selectProject+" GROUP BY p.id ORDER BY p.name")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения проектов из БД: %w", err)
+	}
+
+	defer rows.Close()
+
+	projects := []models.Project{}
+
+	for rows.Next() {
+		var project models.Project
+
+		if err = rows.Scan(&project.ID, &project.Name, &project.Count); err != nil {
+			return nil, fmt.Errorf("ошибка получения проектов из БД: %w", err)
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения проектов из БД: %w", err)
+	}
+
+	return projects, nil
+}
+
+// GetProject получает проект из БД по его ID.
+func (db *DB) GetProject(ctx context.Context, id int64) (models.Project, error) {
+	var project models.Project
+
+	err := db.db.QueryRowContext(ctx, selectProject+" WHERE p.id = ? GROUP BY p.id", id).
+		Scan(&project.ID, &project.Name, &project.Count)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("ошибка получения проекта из БД: %w", err)
+	}
+
+	return project, nil
+}
+
+// UpdateProject переименовывает проект в БД.
+func (db *DB) UpdateProject(ctx context.Context, id int64, name string) error {
+	row, err := db.db.ExecContext(ctx, "UPDATE projects SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления проекта в БД: %w", projectError(err))
+	}
+
+	checkRow, err := row.RowsAffected()
+
+	if err != nil || checkRow == 0 {
+		return fmt.Errorf("ошибка обновления проекта в БД: %w", errProjectNotFound)
+	}
+
+	return nil
+}
+
+// DeleteProject удаляет проект из БД. Задачи проекта переносятся во входящие, а при удалении
+// каскадом — в корзину.
+func (db *DB) DeleteProject(ctx context.Context, id int64, cascade bool) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if cascade {
+		_, err = tx.ExecContext(ctx, `INSERT INTO trash (task_id, deleted_at, reason)
+            SELECT s.id, ?, ? FROM scheduler s JOIN task_details d ON d.task_id = s.id
+            WHERE d.project_id = ? AND `+notTrashed,
+			time.Now().UTC().Format(time.RFC3339), models.TrashDeleted, id)
+		if err != nil {
+			return fmt.Errorf("ошибка удаления задач проекта: %w", err)
+		}
+	}
+
+	// Внешний ключ task_details.project_id переносит оставшиеся задачи во входящие.
+	row, err := tx.ExecContext(ctx, "DELETE FROM projects WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления проекта: %w", err)
+	}
+
+	checkRow, err := row.RowsAffected()
+
+	if err != nil || checkRow == 0 {
+		return fmt.Errorf("ошибка удаления проекта: %w", errProjectNotFound)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка удаления проекта: %w", err)
+	}
+
+	return nil
+}
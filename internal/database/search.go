@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// markOpen и markClose выделяют совпадения в заголовке и фрагменте комментария.
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+	// minStem наименьшая длина слова после отбрасывания окончания.
+	minStem = 3
+	// russianEndings буквы, которые отбрасываются с конца русских слов перед поиском по префиксу.
+	russianEndings = "аеёиоуыэюяйь"
+)
+
+// searchTriggerPrefix начало имен триггеров, которые поддерживают полнотекстовый индекс в актуальном состоянии,
+// а searchTriggers — их окончания.
+const searchTriggerPrefix = "scheduler_search_"
+
+var searchTriggers = []string{"insert", "update", "delete"}
+
+// searchSchema полнотекстовый индекс по заголовку и комментарию задач и триггеры, которые его обновляют.
+// Подчеркивание считается частью слова, чтобы «50_» не находило «50%».
+const searchSchema = `CREATE VIRTUAL TABLE IF NOT EXISTS scheduler_fts USING fts5 (
+        title, comment, tokenize = "unicode61 remove_diacritics 2 tokenchars '_'", prefix = '2 3'
+    );
+    CREATE TRIGGER IF NOT EXISTS scheduler_search_insert AFTER INSERT ON scheduler BEGIN
+        INSERT INTO scheduler_fts (rowid, title, comment) VALUES (new.id, new.title, COALESCE(new.comment, ''));
+    END;
+    CREATE TRIGGER IF NOT EXISTS scheduler_search_update AFTER UPDATE OF title, comment ON scheduler BEGIN
+        UPDATE scheduler_fts SET title = new.title, comment = COALESCE(new.comment, '') WHERE rowid = old.id;
+    END;
+    CREATE TRIGGER IF NOT EXISTS scheduler_search_delete AFTER DELETE ON scheduler BEGIN
+        DELETE FROM scheduler_fts WHERE rowid = old.id;
+    END;`
+
+// rebuildSearch заново заполняет полнотекстовый индекс всеми задачами.
+const rebuildSearch = `DELETE FROM scheduler_fts;
+    INSERT INTO scheduler_fts (rowid, title, comment) SELECT id, title, COALESCE(comment, '') FROM scheduler;`
+
+// selectSearch запрос задач, найденных по полнотекстовому индексу, с выделенными совпадениями.
+const selectSearch = `SELECT ` + taskColumns + `,
+        highlight(scheduler_fts, 0, '` + markOpen + `', '` + markClose + `'),
+        snippet(scheduler_fts, 1, '` + markOpen + `', '` + markClose + `', '…', 12)
+    FROM scheduler_fts f JOIN scheduler s ON s.id = f.rowid LEFT JOIN task_details d ON d.task_id = s.id`
+
+// searchOrder упорядочивает найденные задачи по релевантности; совпадение в заголовке весит больше.
+const searchOrder = "bm25(scheduler_fts, 10.0, 1.0), " + taskOrder
+
+// setupSearch создает полнотекстовый индекс, если SQLite собран с FTS5 (тег сборки sqlite_fts5), и сообщает,
+// доступен ли он. Без FTS5 триггеры индекса удаляются, чтобы таблицу задач можно было изменять, а поиск
+// выполняется через LIKE. Если триггеров не было, пока задачи изменялись без них, индекс строится заново.
+func setupSearch(ctx context.Context, db *sql.DB) (bool, error) {
+	var enabled bool
+
+	if err := db.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return false, fmt.Errorf("ошибка проверки поддержки полнотекстового поиска: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var triggers int
+
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE ?",
+		searchTriggerPrefix+"%").Scan(&triggers)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки полнотекстового индекса: %w", err)
+	}
+
+	var queries []string
+
+	switch {
+	case !enabled:
+		for _, e := range searchTriggers {
+			queries = append(queries, "DROP TRIGGER IF EXISTS "+searchTriggerPrefix+e)
+		}
+	case triggers < len(searchTriggers):
+		queries = []string{searchSchema, rebuildSearch}
+	}
+
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return false, fmt.Errorf("ошибка создания полнотекстового индекса: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка создания полнотекстового индекса: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// ftsQuery строит запрос FTS5: каждое слово ищется по префиксу; если stemmed, у русских слов
+// отбрасывается окончание, чтобы «кактусы» находило и «кактуса». Пустая строка означает, что искать нечего.
+func ftsQuery(search string, stemmed bool) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_'
+	})
+
+	terms := make([]string, 0, len(words))
+
+	for _, e := range words {
+		if stemmed {
+			e = stem(e)
+		}
+
+		terms = append(terms, `"`+e+`"*`)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// stem отбрасывает окончание русского слова, оставляя не меньше minStem букв.
+func stem(word string) string {
+	if !strings.ContainsFunc(word, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }) {
+		return word
+	}
+
+	for utf8.RuneCountInString(word) > minStem {
+		last, size := utf8.DecodeLastRuneInString(word)
+		if !strings.ContainsRune(russianEndings, last) {
+			break
+		}
+
+		word = word[:len(word)-size]
+	}
+
+	return word
+}
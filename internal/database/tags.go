@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Memonagi/go_final_project/internal/models"
+)
+
+// tagSeparator разделяет метки задачи при выборке одним столбцом; в названии метки запятая не допускается.
+const tagSeparator = ","
+
+// taskTags столбец с метками задачи через запятую.
+const taskTags = `COALESCE((SELECT group_concat(g.name, '` + tagSeparator + `')
+        FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id = s.id), '')`
+
+// hasTag условие отбора задач с меткой.
+const hasTag = "EXISTS (SELECT 1 FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id = s.id AND g.name = ?)"
+
+// deleteUnusedTags запрос удаления меток, которые не назначены ни одной задаче.
+const deleteUnusedTags = "DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM task_tags)"
+
+// splitTags разбирает метки задачи, полученные столбцом taskTags, и упорядочивает их по названию.
+func splitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+
+	names := strings.Split(tags, tagSeparator)
+	slices.Sort(names)
+
+	return names
+}
+
+// tagConditions возвращает условия отбора задач по меткам: задача должна иметь все метки tags
+// и не иметь ни одной из меток excluded.
+func tagConditions(tags []string, excluded []string) ([]string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+
+	for _, e := range tags {
+		where = append(where, hasTag)
+		args = append(args, e)
+	}
+
+	for _, e := range excluded {
+		where = append(where, "NOT "+hasTag)
+		args = append(args, e)
+	}
+
+	return where, args
+}
+
+// setTags заменяет метки задачи и удаляет метки, которые больше никому не назначены.
+func setTags(ctx context.Context, tx *sql.Tx, id any, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM task_tags WHERE task_id = ?", id); err != nil {
+		return fmt.Errorf("ошибка сохранения меток задачи: %w", err)
+	}
+
+	for _, e := range tags {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING", e); err != nil {
+			return fmt.Errorf("ошибка сохранения меток задачи: %w", err)
+		}
+
+		_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO task_tags (task_id, tag_id)
+            SELECT ?, id FROM tags WHERE name = ?`, id, e)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения меток задачи: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteUnusedTags); err != nil {
+		return fmt.Errorf("ошибка сохранения меток задачи: %w", err)
+	}
+
+	return nil
+}
+
+// GetTags получает из БД метки с числом задач, которым они назначены; задачи из корзины не учитываются.
+func (db *DB) GetTags(ctx context.Context) ([]models.Tag, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT g.name, count(s.id) AS tasks
+        FROM tags g JOIN task_tags tt ON tt.tag_id = g.id JOIN scheduler s ON s.id = tt.task_id
+        WHERE `+notTrashed+` GROUP BY g.id ORDER BY tasks DESC, g.name`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения меток из БД: %w", err)
+	}
+
+	defer rows.Close()
+
+	tags := []models.Tag{}
+
+	for rows.Next() {
+		var tag models.Tag
+
+		if err = rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("ошибка получения меток из БД: %w", err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения меток из БД: %w", err)
+	}
+
+	return tags, nil
+}
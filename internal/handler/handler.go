@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/Memonagi/go_final_project/internal/date"
 	"github.com/Memonagi/go_final_project/internal/models"
 	"github.com/Memonagi/go_final_project/internal/service"
+	"github.com/Memonagi/go_final_project/pkg/date"
 	"github.com/go-chi/chi/v5"
 	"github.com/sirupsen/logrus"
 )
@@ -22,9 +25,11 @@ type Handler struct {
 }
 
 const (
+	defaultDates   = 10
 	readHeaderTime = 5 * time.Second
 	ctxTimeout     = 10 * time.Second
 	dateFormat     = "20060102"
+	dateTimeFormat = "20060102 15:04"
 	webDir         = "./web"
 )
 
@@ -46,13 +51,31 @@ func New(port int, service *service.Service) *Handler {
 
 	r.Route("/api", func(r chi.Router) {
 		r.Get("/nextdate", h.getNextDate)
+		r.Get("/nextdates", h.getNextDates)
+		r.Get("/describe", h.getDescription)
+		r.Post("/rules/validate", h.validateRule)
 		r.Get("/tasks", h.getAllTasks)
+		r.Get("/trash", h.getTrash)
+		r.Get("/tags", h.getTags)
+		r.Get("/projects", h.getProjects)
+		r.Route("/project", func(r chi.Router) {
+			r.Post("/", h.addProject)
+			r.Get("/", h.getProject)
+			r.Put("/", h.updateProject)
+			r.Delete("/", h.deleteProject)
+		})
 		r.Route("/task", func(r chi.Router) {
 			r.Post("/", h.addTask)
 			r.Get("/", h.getTaskID)
 			r.Put("/", h.updateTaskID)
 			r.Post("/done", h.taskDone)
+			r.Post("/quick", h.quickAddTask)
+			r.Get("/history", h.getHistory)
+			r.Post("/restore", h.restoreTask)
 			r.Delete("/", h.deleteTask)
+			r.Get("/exceptions", h.getExceptions)
+			r.Post("/exceptions", h.addException)
+			r.Delete("/exceptions", h.deleteException)
 		})
 	})
 
@@ -125,7 +148,14 @@ func (h *Handler) getNextDate(w http.ResponseWriter, r *http.Request) {
 	dateReq := r.FormValue("date")
 	repeatReq := r.FormValue("repeat")
 
-	now, err := time.Parse(dateFormat, nowReq)
+	loc, err := time.LoadLocation(r.FormValue("tz"))
+	if err != nil {
+		http.Error(w, "неизвестный часовой пояс", http.StatusInternalServerError)
+
+		return
+	}
+
+	now, err := time.ParseInLocation(dateFormat, nowReq, loc)
 	if err != nil {
 		http.Error(w, "неправильный формат даты", http.StatusInternalServerError)
 
@@ -147,6 +177,138 @@ func (h *Handler) getNextDate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getNextDates GET-обработчик для получения нескольких следующих дат по правилу.
+// Без now отсчет ведется от текущей даты, без count и until возвращается 10 дат.
+// Для правил h, min и cron в now можно указать время (20060102 15:04), а в time — время задачи.
+func (h *Handler) getNextDates(w http.ResponseWriter, r *http.Request) {
+	loc, err := time.LoadLocation(r.FormValue("tz"))
+	if err != nil {
+		errorResponse(w, "неизвестный часовой пояс", err)
+
+		return
+	}
+
+	now := time.Now().In(loc)
+
+	if nowReq := r.FormValue("now"); nowReq != "" {
+		layout := dateFormat
+		if len(nowReq) > len(dateFormat) {
+			layout = dateTimeFormat
+		}
+
+		now, err = time.ParseInLocation(layout, nowReq, loc)
+		if err != nil {
+			errorResponse(w, "неправильный формат даты", err)
+
+			return
+		}
+	}
+
+	count := defaultDates
+	until := r.FormValue("until")
+
+	if until != "" {
+		count = date.MaxOccurrences
+	}
+
+	if countReq := r.FormValue("count"); countReq != "" {
+		count, err = strconv.Atoi(countReq)
+		if err != nil {
+			errorResponse(w, "неправильное количество дат", err)
+
+			return
+		}
+	}
+
+	dates, err := date.NextDates(now, r.FormValue("date"), r.FormValue("time"), r.FormValue("repeat"), count, until)
+	if err != nil {
+		errorResponse(w, "ошибка вычисления следующих дат", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, models.Dates{Dates: dates})
+}
+
+// getDescription GET-обработчик для получения понятного описания правила повторения.
+func (h *Handler) getDescription(w http.ResponseWriter, r *http.Request) {
+	description, err := date.Describe(r.FormValue("repeat"), language(r))
+	if err != nil {
+		errorResponse(w, "не удалось описать правило повторения", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, models.Description{Description: description})
+}
+
+// validateRule POST-обработчик для проверки правила повторения.
+// Для неверного правила возвращает код ошибки, неверную часть правила, ее позицию и подсказку.
+func (h *Handler) validateRule(w http.ResponseWriter, r *http.Request) {
+	var request models.RuleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		errorResponse(w, "ошибка десериализации JSON", err)
+
+		return
+	}
+
+	if diagnostic := date.Diagnose(request.Repeat); diagnostic != nil {
+		//nolint:exhaustivestruct
+		okResponse(w, http.StatusOK, models.Validation{Diagnostic: diagnostic})
+
+		return
+	}
+
+	rule, err := date.Parse(request.Repeat)
+	if err != nil {
+		errorResponse(w, "не удалось разобрать правило повторения", err)
+
+		return
+	}
+
+	repeat := rule.String()
+
+	description, err := date.Describe(repeat, language(r))
+	if err != nil {
+		errorResponse(w, "не удалось описать правило повторения", err)
+
+		return
+	}
+
+	//nolint:exhaustivestruct
+	okResponse(w, http.StatusOK, models.Validation{Valid: true, Repeat: repeat, Description: description})
+}
+
+// language определяет язык описаний по параметру lang или заголовку Accept-Language.
+func language(r *http.Request) string {
+	lang := r.URL.Query().Get("lang")
+
+	if lang == "" {
+		lang = r.Header.Get("Accept-Language")
+	}
+
+	if strings.HasPrefix(strings.ToLower(lang), date.LangEN) {
+		return date.LangEN
+	}
+
+	return date.LangRU
+}
+
+// describeRepeat возвращает описание правила повторения или пустую строку, если правило не разобрано.
+func describeRepeat(repeat string, lang string) string {
+	if repeat == "" {
+		return ""
+	}
+
+	description, err := date.Describe(repeat, lang)
+	if err != nil {
+		return ""
+	}
+
+	return description
+}
+
 // addTask POST-обработчик для добавления новой задачи.
 func (h *Handler) addTask(w http.ResponseWriter, r *http.Request) {
 	var task models.Task
@@ -170,15 +332,54 @@ func (h *Handler) addTask(w http.ResponseWriter, r *http.Request) {
 	okResponse(w, http.StatusCreated, response)
 }
 
+// quickAddTask POST-обработчик для добавления задачи по строке на естественном языке.
+// Возвращает добавленную задачу, чтобы клиент мог проверить, как разобрана строка.
+func (h *Handler) quickAddTask(w http.ResponseWriter, r *http.Request) {
+	var request models.QuickRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		errorResponse(w, "ошибка десериализации JSON", err)
+
+		return
+	}
+
+	task, err := h.service.QuickAdd(r.Context(), request.Text)
+	if err != nil {
+		errorResponse(w, "не удалось добавить новую задачу", err)
+
+		return
+	}
+
+	task.RepeatDescription = describeRepeat(task.Repeat, language(r))
+
+	okResponse(w, http.StatusCreated, task)
+}
+
 // getAllTasks GET-обработчик для получения списка ближайших задач.
+// Параметр search отбирает задачи по заголовку и комментарию или по дате в формате 02.01.2006,
+// параметр priority — по приоритетам через запятую, повторяемый параметр tag — по меткам
+// (tag=-метка исключает задачи с меткой), параметр project — по ID проекта или inbox для задач без проекта.
 func (h *Handler) getAllTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.GetAllTasks(r.Context())
+	query := models.TaskQuery{
+		Search:   r.URL.Query().Get("search"),
+		Priority: r.URL.Query().Get("priority"),
+		Tags:     r.URL.Query()["tag"],
+		Project:  r.URL.Query().Get("project"),
+	}
+
+	tasks, err := h.service.GetAllTasks(r.Context(), query)
 	if err != nil {
 		errorResponse(w, "не удалось получить список ближайших задач", err)
 
 		return
 	}
 
+	lang := language(r)
+
+	for i := range tasks {
+		tasks[i].RepeatDescription = describeRepeat(tasks[i].Repeat, lang)
+	}
+
 	//nolint:exhaustivestruct
 	response := models.Response{Tasks: tasks}
 
@@ -196,6 +397,8 @@ func (h *Handler) getTaskID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	taskStruct.RepeatDescription = describeRepeat(taskStruct.Repeat, language(r))
+
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 	w.WriteHeader(http.StatusCreated)
@@ -228,11 +431,19 @@ func (h *Handler) updateTaskID(w http.ResponseWriter, r *http.Request) {
 	okResponse(w, http.StatusOK, updateTask)
 }
 
-// taskDone POST-обработчик для выполнения задачи.
+// taskDone POST-обработчик для выполнения задачи. В теле запроса можно передать заметку: {"note":"текст"}.
 func (h *Handler) taskDone(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 
-	if err := h.service.TaskDone(r.Context(), id); err != nil {
+	var request models.DoneRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		errorResponse(w, "ошибка десериализации JSON", err)
+
+		return
+	}
+
+	if err := h.service.TaskDone(r.Context(), id, request.Note); err != nil {
 		errorResponse(w, "не удалось отметить задачу выполненной", err)
 
 		return
@@ -243,6 +454,20 @@ func (h *Handler) taskDone(w http.ResponseWriter, r *http.Request) {
 	okResponse(w, http.StatusOK, response)
 }
 
+// getHistory GET-обработчик для получения истории выполнений задачи.
+func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	history, err := h.service.GetHistory(r.Context(), id)
+	if err != nil {
+		errorResponse(w, "не удалось получить историю выполнений задачи", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, models.History{History: history})
+}
+
 // deleteTask DELETE-обработчик для удаления задачи.
 func (h *Handler) deleteTask(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
@@ -257,3 +482,172 @@ func (h *Handler) deleteTask(w http.ResponseWriter, r *http.Request) {
 
 	okResponse(w, http.StatusOK, response)
 }
+
+// getTrash GET-обработчик для получения списка задач из корзины.
+func (h *Handler) getTrash(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.service.GetTrash(r.Context())
+	if err != nil {
+		errorResponse(w, "не удалось получить корзину", err)
+
+		return
+	}
+
+	//nolint:exhaustivestruct
+	response := models.Response{Tasks: tasks}
+
+	okResponse(w, http.StatusOK, response)
+}
+
+// getTags GET-обработчик для получения списка меток с числом задач.
+func (h *Handler) getTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.service.GetTags(r.Context())
+	if err != nil {
+		errorResponse(w, "не удалось получить список меток", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, models.Tags{Tags: tags})
+}
+
+// getProjects GET-обработчик для получения списка проектов.
+func (h *Handler) getProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.service.GetProjects(r.Context())
+	if err != nil {
+		errorResponse(w, "не удалось получить список проектов", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, models.Projects{Projects: projects})
+}
+
+// addProject POST-обработчик для добавления проекта.
+func (h *Handler) addProject(w http.ResponseWriter, r *http.Request) {
+	var project models.Project
+
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		errorResponse(w, "ошибка десериализации JSON", err)
+
+		return
+	}
+
+	id, err := h.service.AddProject(r.Context(), project)
+	if err != nil {
+		errorResponse(w, "не удалось добавить проект", err)
+
+		return
+	}
+
+	//nolint:exhaustivestruct
+	okResponse(w, http.StatusCreated, models.Response{ID: id})
+}
+
+// getProject GET-обработчик для получения проекта по его id.
+func (h *Handler) getProject(w http.ResponseWriter, r *http.Request) {
+	project, err := h.service.GetProject(r.Context(), r.URL.Query().Get("id"))
+	if err != nil {
+		errorResponse(w, "не удалось получить проект", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, project)
+}
+
+// updateProject PUT-обработчик для переименования проекта.
+func (h *Handler) updateProject(w http.ResponseWriter, r *http.Request) {
+	var project models.Project
+
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		errorResponse(w, "ошибка десериализации JSON", err)
+
+		return
+	}
+
+	updated, err := h.service.UpdateProject(r.Context(), project)
+	if err != nil {
+		errorResponse(w, "не удалось переименовать проект", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, updated)
+}
+
+// deleteProject DELETE-обработчик для удаления проекта. Параметр mode определяет судьбу задач проекта:
+// inbox (по умолчанию) переносит их во входящие, cascade — в корзину.
+func (h *Handler) deleteProject(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if err := h.service.DeleteProject(r.Context(), q.Get("id"), q.Get("mode")); err != nil {
+		errorResponse(w, "не удалось удалить проект", err)
+
+		return
+	}
+
+	response := struct{}{}
+
+	okResponse(w, http.StatusOK, response)
+}
+
+// restoreTask POST-обработчик для восстановления задачи из корзины.
+func (h *Handler) restoreTask(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	if err := h.service.RestoreTask(r.Context(), id); err != nil {
+		errorResponse(w, "не удалось восстановить задачу", err)
+
+		return
+	}
+
+	response := struct{}{}
+
+	okResponse(w, http.StatusOK, response)
+}
+
+// getExceptions GET-обработчик для получения дат, исключенных из повторений задачи.
+func (h *Handler) getExceptions(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	exceptions, err := h.service.GetExceptions(r.Context(), id)
+	if err != nil {
+		errorResponse(w, "не удалось получить исключения задачи", err)
+
+		return
+	}
+
+	okResponse(w, http.StatusOK, models.Exceptions{Exceptions: exceptions})
+}
+
+// addException POST-обработчик для исключения даты из повторений задачи.
+func (h *Handler) addException(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	dateReq := r.URL.Query().Get("date")
+
+	if err := h.service.AddException(r.Context(), id, dateReq); err != nil {
+		errorResponse(w, "не удалось добавить исключение", err)
+
+		return
+	}
+
+	response := struct{}{}
+
+	okResponse(w, http.StatusOK, response)
+}
+
+// deleteException DELETE-обработчик для возврата даты в повторения задачи.
+func (h *Handler) deleteException(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	dateReq := r.URL.Query().Get("date")
+
+	if err := h.service.DeleteException(r.Context(), id, dateReq); err != nil {
+		errorResponse(w, "не удалось удалить исключение", err)
+
+		return
+	}
+
+	response := struct{}{}
+
+	okResponse(w, http.StatusOK, response)
+}
@@ -1,17 +1,183 @@
 package models
 
+import "github.com/Memonagi/go_final_project/pkg/date"
+
 // Task структура задач.
 type Task struct {
-	ID      string `json:"id"`
-	Date    string `json:"date"`
-	Title   string `json:"title"`
-	Comment string `json:"comment"`
-	Repeat  string `json:"repeat"`
+	ID       string `json:"id"`
+	Date     string `json:"date"`
+	Title    string `json:"title"`
+	Comment  string `json:"comment"`
+	Repeat   string `json:"repeat"`
+	Time     string `json:"time,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Until    string `json:"until,omitempty"`
+	Count    string `json:"count,omitempty"`
+	Anchor   string `json:"anchor,omitempty"`
+	// Progress число выполнений в текущем периоде для правила q; вычисляется по истории выполнений, в БД не хранится.
+	Progress string `json:"progress,omitempty"`
+	// Priority приоритет задачи от 1 (высший) до 4 (низший).
+	Priority string `json:"priority,omitempty"`
+	// ProjectID проект задачи; задача без проекта находится во входящих.
+	ProjectID string `json:"project_id,omitempty"`
+	// Tags метки задачи, упорядоченные по названию.
+	Tags []string `json:"tags,omitempty"`
+	// Rule правило повторения в виде структуры, заменяет строку Repeat в запросах; в БД хранится как строка.
+	Rule *date.Rule `json:"rule,omitempty"`
+	// RepeatDescription понятное описание правила повторения, в БД не хранится.
+	RepeatDescription string `json:"repeat_description,omitempty"`
+	// Highlight заголовок с выделенными совпадениями поиска, Snippet фрагмент комментария с совпадениями.
+	Highlight string `json:"highlight,omitempty"`
+	Snippet   string `json:"snippet,omitempty"`
+	// DeletedAt момент переноса в корзину, DeleteReason причина: deleted или done; заполняются только в корзине.
+	DeletedAt    string `json:"deleted_at,omitempty"`
+	DeleteReason string `json:"delete_reason,omitempty"`
+}
+
+// Приоритеты задачи.
+const (
+	// HighestPriority высший приоритет.
+	HighestPriority = 1
+	// LowestPriority низший приоритет, он же приоритет задач, для которых приоритет не указан.
+	LowestPriority = 4
+)
+
+// Режимы отсчета повторений задачи.
+const (
+	// AnchorCalendar следующая дата отсчитывается от даты задачи (по умолчанию).
+	AnchorCalendar = "calendar"
+	// AnchorCompletion следующая дата отсчитывается от даты фактического выполнения.
+	AnchorCompletion = "completion"
+)
+
+// TaskFilter условия отбора задач для списка ближайших задач.
+type TaskFilter struct {
+	// Search подстрока заголовка или комментария без учета регистра.
+	Search string
+	// Date дата задачи в формате 20060102.
+	Date string
+	// Priorities приоритеты задач; задачи без приоритета считаются задачами с низшим приоритетом.
+	Priorities []int
+	// Tags метки, которые должны быть у задачи, ExcludedTags метки, которых у задачи быть не должно.
+	Tags         []string
+	ExcludedTags []string
+	// Project ID проекта или ProjectInbox для задач без проекта.
+	Project string
+}
+
+// TaskQuery параметры запроса списка задач.
+type TaskQuery struct {
+	// Search строка поиска: текст или дата в формате 02.01.2006.
+	Search string
+	// Priority приоритеты через запятую, например 1,2.
+	Priority string
+	// Tags метки; метка с минусом в начале исключает задачи с этой меткой.
+	Tags []string
+	// Project ID проекта или inbox.
+	Project string
+}
+
+// Tag метка задач с числом задач, которым она назначена.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
 }
 
+// Tags структура отображения списка меток.
+type Tags struct {
+	Tags []Tag `json:"tags"`
+}
+
+// Project проект, объединяющий задачи, с числом задач в нем (без задач из корзины).
+type Project struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Projects структура отображения списка проектов.
+type Projects struct {
+	Projects []Project `json:"projects"`
+}
+
+// ProjectInbox входящие: в фильтре списка задач обозначает задачи без проекта.
+const ProjectInbox = "inbox"
+
+// Что делать с задачами при удалении проекта.
+const (
+	// ProjectDeleteInbox задачи переносятся во входящие (по умолчанию).
+	ProjectDeleteInbox = "inbox"
+	// ProjectDeleteCascade задачи переносятся в корзину вместе с удалением проекта.
+	ProjectDeleteCascade = "cascade"
+)
+
+// Причины переноса задачи в корзину.
+const (
+	// TrashDeleted задача удалена пользователем.
+	TrashDeleted = "deleted"
+	// TrashDone разовая задача выполнена.
+	TrashDone = "done"
+)
+
 // Response структура отображения ответа.
 type Response struct {
 	ID    string `json:"id,omitempty"`
 	Error string `json:"error,omitempty"`
 	Tasks []Task `json:"tasks"`
 }
+
+// Exceptions структура отображения дат, исключенных из повторений задачи.
+type Exceptions struct {
+	Exceptions []string `json:"exceptions"`
+}
+
+// Completion запись истории выполнений задачи.
+type Completion struct {
+	ID     string `json:"id"`
+	TaskID string `json:"task_id"`
+	// Date и Time дата и время выполненного повторения задачи.
+	Date string `json:"date"`
+	Time string `json:"time,omitempty"`
+	// DoneAt момент отметки о выполнении в формате RFC 3339.
+	DoneAt string `json:"done_at"`
+	Note   string `json:"note,omitempty"`
+}
+
+// DoneRequest необязательное тело запроса на выполнение задачи.
+type DoneRequest struct {
+	Note string `json:"note"`
+}
+
+// History структура отображения истории выполнений задачи.
+type History struct {
+	History []Completion `json:"history"`
+}
+
+// Dates структура отображения списка дат повторений.
+type Dates struct {
+	Dates []string `json:"dates"`
+}
+
+// Description структура отображения описания правила повторения.
+type Description struct {
+	Description string `json:"description"`
+}
+
+// QuickRequest структура запроса на быстрое добавление задачи одной строкой.
+type QuickRequest struct {
+	Text string `json:"text"`
+}
+
+// RuleRequest структура запроса на проверку правила повторения.
+type RuleRequest struct {
+	Repeat string `json:"repeat"`
+}
+
+// Validation структура отображения результата проверки правила повторения.
+type Validation struct {
+	Valid bool `json:"valid"`
+	// Repeat каноническая запись верного правила.
+	Repeat      string           `json:"repeat,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Diagnostic  *date.Diagnostic `json:"diagnostic,omitempty"`
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Memonagi/go_final_project/internal/models"
+)
+
+// maxProjectName наибольшая длина названия проекта.
+const maxProjectName = 64
+
+var (
+	errProjectName = errors.New("название проекта не может быть пустым или длиннее 64 символов")
+	errProject     = errors.New("проект задачи не найден")
+	errProjectMode = errors.New("при удалении проекта задачи переносятся во входящие (inbox) или в корзину (cascade)")
+)
+
+// checkProjectName проверяет название проекта и возвращает его без пробелов по краям.
+func checkProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" || utf8.RuneCountInString(name) > maxProjectName {
+		return "", fmt.Errorf("%w", errProjectName)
+	}
+
+	return name, nil
+}
+
+// projectID разбирает ID проекта.
+func projectID(id string) (int64, error) {
+	if id == "" {
+		return 0, fmt.Errorf("%w", errID)
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	return int64(idInt), nil
+}
+
+// checkProject проверяет, что проект задачи существует.
+func (s *Service) checkProject(ctx context.Context, task models.Task) error {
+	if task.ProjectID == "" {
+		return nil
+	}
+
+	id, err := strconv.Atoi(task.ProjectID)
+	if err != nil {
+		return fmt.Errorf("%w", errProject)
+	}
+
+	if _, err = s.db.GetProject(ctx, int64(id)); err != nil {
+		return fmt.Errorf("%w: %w", errProject, err)
+	}
+
+	return nil
+}
+
+// projectFilter проверяет проект из параметров запроса списка задач: ID проекта или inbox.
+func projectFilter(project string) (string, error) {
+	if project == "" || project == models.ProjectInbox {
+		return project, nil
+	}
+
+	if _, err := strconv.Atoi(project); err != nil {
+		return "", fmt.Errorf("%w", errProject)
+	}
+
+	return project, nil
+}
+
+// AddProject добавляет новый проект.
+func (s *Service) AddProject(ctx context.Context, project models.Project) (string, error) {
+	name, err := checkProjectName(project.Name)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.db.AddProject(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("ошибка добавления проекта: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetProjects получает список проектов.
+func (s *Service) GetProjects(ctx context.Context) ([]models.Project, error) {
+	projects, err := s.db.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения проектов: %w", err)
+	}
+
+	return projects, nil
+}
+
+// GetProject получает проект по его ID.
+func (s *Service) GetProject(ctx context.Context, id string) (models.Project, error) {
+	idInt, err := projectID(id)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	project, err := s.db.GetProject(ctx, idInt)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("ошибка получения проекта: %w", err)
+	}
+
+	return project, nil
+}
+
+// UpdateProject переименовывает проект.
+func (s *Service) UpdateProject(ctx context.Context, project models.Project) (models.Project, error) {
+	idInt, err := projectID(project.ID)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	name, err := checkProjectName(project.Name)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	if err = s.db.UpdateProject(ctx, idInt, name); err != nil {
+		return models.Project{}, fmt.Errorf("ошибка обновления проекта: %w", err)
+	}
+
+	return s.GetProject(ctx, project.ID)
+}
+
+// DeleteProject удаляет проект; задачи проекта переносятся во входящие или, в режиме cascade, в корзину.
+func (s *Service) DeleteProject(ctx context.Context, id string, mode string) error {
+	idInt, err := projectID(id)
+	if err != nil {
+		return err
+	}
+
+	if mode != "" && mode != models.ProjectDeleteInbox && mode != models.ProjectDeleteCascade {
+		return fmt.Errorf("%w", errProjectMode)
+	}
+
+	if err = s.db.DeleteProject(ctx, idInt, mode == models.ProjectDeleteCascade); err != nil {
+		return fmt.Errorf("ошибка удаления проекта: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Memonagi/go_final_project/internal/models"
+	"github.com/Memonagi/go_final_project/pkg/date"
+)
+
+const (
+	daysInWeek  = 7
+	maxMonthDay = 31
+)
+
+// quickDays относительные дни: сколько дней прибавить к сегодняшней дате.
+var quickDays = map[string]int{
+	"сегодня":     0,
+	"today":       0,
+	"завтра":      1,
+	"tomorrow":    1,
+	"послезавтра": 2,
+}
+
+// quickWeekdays названия дней недели (1 — понедельник, 7 — воскресенье).
+var quickWeekdays = map[string]int{
+	"понедельник": 1, "вторник": 2, "среда": 3, "среду": 3, "четверг": 4,
+	"пятница": 5, "пятницу": 5, "суббота": 6, "субботу": 6, "воскресенье": 7,
+	"monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4, "friday": 5, "saturday": 6, "sunday": 7,
+}
+
+// quickWeekdaysPlural названия дней недели во множественном числе: «по понедельникам», «on mondays».
+var quickWeekdaysPlural = map[string]int{
+	"понедельникам": 1, "вторникам": 2, "средам": 3, "четвергам": 4,
+	"пятницам": 5, "субботам": 6, "воскресеньям": 7,
+	"mondays": 1, "tuesdays": 2, "wednesdays": 3, "thursdays": 4, "fridays": 5, "saturdays": 6, "sundays": 7,
+}
+
+// quickUnits единицы интервалов: дни, недели, месяцы и годы.
+var quickUnits = map[string]string{
+	"день": "d", "дня": "d", "дней": "d", "day": "d", "days": "d",
+	"неделю": "w", "недели": "w", "недель": "w", "week": "w", "weeks": "w",
+	"месяц": "m", "месяца": "m", "месяцев": "m", "month": "m", "months": "m",
+	"год": "y", "года": "y", "лет": "y", "year": "y", "years": "y",
+}
+
+// quickRepeats слова, которые сами задают правило повторения.
+var quickRepeats = map[string]string{
+	"ежедневно": "d", "daily": "d",
+	"еженедельно": "w", "weekly": "w",
+	"ежемесячно": "m", "monthly": "m",
+	"ежегодно": "y", "yearly": "y", "annually": "y",
+}
+
+// quickGroups группы дней недели: будни и выходные.
+var quickGroups = map[string]string{
+	"будням":   "w 1,2,3,4,5",
+	"weekdays": "w 1,2,3,4,5",
+	"выходным": "w 6,7",
+	"weekends": "w 6,7",
+}
+
+// quickTask разбор строки быстрого добавления задачи.
+type quickTask struct {
+	now   time.Time
+	words []string
+	date  time.Time
+	time  string
+	// unit единица повторения из «каждый день», «каждую неделю» и т.п.
+	unit     string
+	interval int
+	weekdays []int
+	repeat   string
+	monthDay int
+	tags     []string
+}
+
+// QuickAdd разбирает строку на русском или английском языке («Позвонить маме завтра», «Pay rent every month
+// on the 5th»), добавляет задачу и возвращает ее. Слова вида #метка становятся метками задачи,
+// остальные нераспознанные слова — ее заголовком.
+func (s *Service) QuickAdd(ctx context.Context, text string) (models.Task, error) {
+	now, err := s.now(models.Task{})
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task := parseQuick(text, now)
+
+	id, err := s.AddTask(ctx, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return s.GetTaskID(ctx, id)
+}
+
+// parseQuick разбирает строку быстрого добавления в задачу.
+func parseQuick(text string, now time.Time) models.Task {
+	original := strings.Fields(text)
+	q := quickTask{now: now, words: make([]string, 0, len(original))}
+
+	for _, e := range original {
+		q.words = append(q.words, strings.ToLower(strings.Trim(e, ",.!?;")))
+	}
+
+	var title []string
+
+	for i := 0; i < len(q.words); {
+		n := q.match(i)
+		if n == 0 {
+			title = append(title, original[i])
+			n = 1
+		}
+
+		i += n
+	}
+
+	repeat := q.rule()
+	q.firstDate(repeat)
+
+	//nolint:exhaustivestruct
+	task := models.Task{
+		Title:  strings.Join(title, " "),
+		Repeat: repeat,
+		Time:   q.time,
+		Tags:   q.tags,
+	}
+
+	if !q.date.IsZero() {
+		task.Date = q.date.Format(dateFormat)
+	}
+
+	return task
+}
+
+// word возвращает нормализованное слово или пустую строку за пределами строки.
+func (q *quickTask) word(i int) string {
+	if i < 0 || i >= len(q.words) {
+		return ""
+	}
+
+	return q.words[i]
+}
+
+// match распознает фразу, начинающуюся с i-го слова, и возвращает число разобранных слов.
+func (q *quickTask) match(i int) int {
+	w := q.word(i)
+
+	if strings.HasPrefix(w, "#") {
+		if tag, err := normalizeTag(w); err == nil {
+			q.tags = append(q.tags, tag)
+
+			return 1
+		}
+	}
+
+	if w == "day" && q.word(i+1) == "after" && q.word(i+2) == "tomorrow" {
+		q.date = q.today().AddDate(0, 0, 2)
+
+		return 3
+	}
+
+	if days, ok := quickDays[w]; ok {
+		q.date = q.today().AddDate(0, 0, days)
+
+		return 1
+	}
+
+	if _, ok := quickWeekdays[w]; ok {
+		return q.matchWeekday(i)
+	}
+
+	switch w {
+	case "в", "во", "on", "next":
+		if _, ok := quickWeekdays[q.word(i+1)]; ok {
+			return 1 + q.matchWeekday(i+1)
+		}
+	}
+
+	if n := q.matchTime(i); n > 0 {
+		return n
+	}
+
+	if n := q.matchMonthDay(i); n > 0 {
+		return n
+	}
+
+	if n := q.matchRepeat(i); n > 0 {
+		return n
+	}
+
+	if day, err := time.ParseInLocation("02.01.2006", w, q.now.Location()); err == nil {
+		q.date = day
+
+		return 1
+	}
+
+	switch w {
+	case "через", "in":
+		return q.matchAfter(i)
+	}
+
+	return 0
+}
+
+// matchWeekday распознает день недели: ближайший такой день становится датой задачи.
+func (q *quickTask) matchWeekday(i int) int {
+	day := quickWeekdays[q.word(i)]
+	today := q.today()
+
+	ahead := (day%daysInWeek - int(today.Weekday()) + daysInWeek) % daysInWeek
+	if ahead == 0 {
+		ahead = daysInWeek
+	}
+
+	q.date = today.AddDate(0, 0, ahead)
+
+	return 1
+}
+
+// matchTime распознает время задачи: «в 18:30», «at 18:30» или «18:30».
+func (q *quickTask) matchTime(i int) int {
+	n := 0
+
+	if w := q.word(i); w == "в" || w == "at" {
+		n = 1
+	}
+
+	clock, err := time.Parse(timeFormat, q.word(i+n))
+	if err != nil {
+		return 0
+	}
+
+	q.time = clock.Format(timeFormat)
+
+	return n + 1
+}
+
+// matchMonthDay распознает день месяца: «5 числа», «5-го числа», «on the 5th».
+func (q *quickTask) matchMonthDay(i int) int {
+	value, n := q.word(i), 2
+
+	if value == "on" && q.word(i+1) == "the" {
+		value, n = q.word(i+2), 3
+		value = strings.TrimRight(value, "stndrh")
+	} else {
+		if q.word(i+1) != "числа" {
+			return 0
+		}
+
+		value = strings.TrimSuffix(value, "-го")
+	}
+
+	day, err := strconv.Atoi(value)
+	if err != nil || day < 1 || day > maxMonthDay {
+		return 0
+	}
+
+	q.monthDay = day
+
+	return n
+}
+
+// matchRepeat распознает правило повторения: «каждый день», «каждые 3 дня», «every monday», «по будням», «ежедневно».
+func (q *quickTask) matchRepeat(i int) int {
+	w := q.word(i)
+
+	if unit, ok := quickRepeats[w]; ok {
+		q.unit, q.interval = unit, 1
+
+		return 1
+	}
+
+	switch w {
+	case "по", "on":
+		if rule, ok := quickGroups[q.word(i+1)]; ok {
+			q.repeat = rule
+
+			return 2
+		}
+
+		if _, ok := quickWeekdaysPlural[q.word(i+1)]; ok {
+			return 1 + q.matchWeekdays(i+1)
+		}
+
+		return 0
+	case "каждый", "каждую", "каждое", "каждые", "every":
+	default:
+		return 0
+	}
+
+	next := q.word(i + 1)
+
+	switch {
+	case next == "рабочий" && q.word(i+2) == "день", next == "business" && q.word(i+2) == "day":
+		q.repeat = "bd 1"
+
+		return 3
+	case next == "weekday":
+		q.repeat = quickGroups["weekdays"]
+
+		return 2
+	case quickUnits[next] != "":
+		q.unit, q.interval = quickUnits[next], 1
+
+		return 2
+	}
+
+	if _, ok := quickWeekdays[next]; ok {
+		return 1 + q.matchWeekdays(i+1)
+	}
+
+	interval, err := strconv.Atoi(next)
+	if err != nil || interval < 1 {
+		return 0
+	}
+
+	switch unit := quickUnits[q.word(i+2)]; {
+	case q.word(i+2) == "число" && interval <= maxMonthDay:
+		q.unit, q.interval, q.monthDay = "m", 1, interval
+	case unit == "d" || unit == "w":
+		q.unit, q.interval = unit, interval
+	default:
+		return 0
+	}
+
+	return 3
+}
+
+// matchWeekdays распознает список дней недели повторения: «понедельник и четверг», «mondays, thursdays».
+func (q *quickTask) matchWeekdays(i int) int {
+	n := 0
+
+	for {
+		if day := weekdayOf(q.word(i + n)); day > 0 {
+			q.weekdays = append(q.weekdays, day)
+			n++
+
+			continue
+		}
+
+		if w := q.word(i + n); (w == "и" || w == "and") && weekdayOf(q.word(i+n+1)) > 0 {
+			n++
+
+			continue
+		}
+
+		return n
+	}
+}
+
+// weekdayOf возвращает номер дня недели по названию в единственном или множественном числе, иначе 0.
+func weekdayOf(word string) int {
+	if day, ok := quickWeekdays[word]; ok {
+		return day
+	}
+
+	return quickWeekdaysPlural[word]
+}
+
+// matchAfter распознает дату через интервал: «через 3 дня», «через неделю», «in 2 weeks».
+func (q *quickTask) matchAfter(i int) int {
+	interval, n := 1, 1
+
+	if value, err := strconv.Atoi(q.word(i + 1)); err == nil && value > 0 {
+		interval, n = value, 2
+	} else if q.word(i+1) == "a" {
+		n = 2
+	}
+
+	var years, months, days int
+
+	switch quickUnits[q.word(i+n)] {
+	case "d":
+		days = interval
+	case "w":
+		days = interval * daysInWeek
+	case "m":
+		months = interval
+	case "y":
+		years = interval
+	default:
+		return 0
+	}
+
+	q.date = q.today().AddDate(years, months, days)
+
+	return n + 1
+}
+
+// rule собирает правило повторения из разобранных частей.
+func (q *quickTask) rule() string {
+	if len(q.weekdays) > 0 {
+		items := make([]string, 0, len(q.weekdays))
+
+		for _, e := range q.weekdays {
+			items = append(items, strconv.Itoa(e))
+		}
+
+		return "w " + strings.Join(items, ",")
+	}
+
+	if q.repeat != "" {
+		return q.repeat
+	}
+
+	switch q.unit {
+	case "d":
+		return "d " + strconv.Itoa(q.interval)
+	case "w":
+		return "d " + strconv.Itoa(q.interval*daysInWeek)
+	case "m":
+		day := q.monthDay
+		if day == 0 {
+			day = q.start().Day()
+		}
+
+		return "m " + strconv.Itoa(day)
+	case "y":
+		return "y"
+	}
+
+	// День месяца без повторения задает ближайшую дату с этим числом.
+	if q.monthDay > 0 && q.date.IsZero() {
+		q.date = q.nextMonthDay()
+	}
+
+	return ""
+}
+
+// firstDate переносит дату задачи с правилом по дням недели или числам месяца на первое повторение
+// не раньше указанной даты или сегодняшнего дня: «каждый месяц 5 числа», добавленная 17-го,
+// назначается на 5-е следующего месяца. Для правил d и y дата задачи остается точкой отсчета.
+func (q *quickTask) firstDate(repeat string) {
+	rule, err := date.Parse(repeat)
+	if err != nil || (rule.Kind != date.KindWeek && rule.Kind != date.KindMonth) {
+		return
+	}
+
+	next, err := rule.Next(q.start().AddDate(0, 0, -1))
+	if err != nil {
+		return
+	}
+
+	q.date = next
+}
+
+// today возвращает начало текущего дня.
+func (q *quickTask) today() time.Time {
+	return time.Date(q.now.Year(), q.now.Month(), q.now.Day(), 0, 0, 0, 0, q.now.Location())
+}
+
+// start возвращает дату задачи или сегодняшнюю дату, если дата не указана.
+func (q *quickTask) start() time.Time {
+	if q.date.IsZero() {
+		return q.today()
+	}
+
+	return q.date
+}
+
+// nextMonthDay возвращает ближайшую, начиная с сегодняшней, дату с указанным числом месяца.
+func (q *quickTask) nextMonthDay() time.Time {
+	today := q.today()
+
+	for month := 0; ; month++ {
+		day := time.Date(today.Year(), today.Month()+time.Month(month), q.monthDay, 0, 0, 0, 0, today.Location())
+		if day.Day() == q.monthDay && !day.Before(today) {
+			return day
+		}
+	}
+}
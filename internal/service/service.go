@@ -9,22 +9,39 @@ import (
 	"time"
 
 	"github.com/Memonagi/go_final_project/internal/database"
-	"github.com/Memonagi/go_final_project/internal/date"
 	"github.com/Memonagi/go_final_project/internal/models"
+	"github.com/Memonagi/go_final_project/pkg/date"
+	"github.com/sirupsen/logrus"
 )
 
-const dateFormat = "20060102"
+const (
+	dateFormat       = "20060102"
+	timeFormat       = "15:04"
+	searchDateFormat = "02.01.2006"
+)
 
 type Service struct {
 	db *database.DB
 }
 
 var (
-	errRule  = errors.New("правило повторения указано в неправильном формате")
-	errDays  = errors.New("указано неверное количество дней")
-	errTitle = errors.New("заголовок задачи не может быть пустым")
-	errDate  = errors.New("неправильный формат даты")
-	errID    = errors.New("не указан ID")
+	errRule      = errors.New("правило повторения указано в неправильном формате")
+	errDays      = errors.New("указано неверное количество дней")
+	errTitle     = errors.New("заголовок задачи не может быть пустым")
+	errDate      = errors.New("неправильный формат даты")
+	errID        = errors.New("не указан ID")
+	errTime      = errors.New("неправильный формат времени")
+	errZone      = errors.New("неизвестный часовой пояс")
+	errCount     = errors.New("количество повторений должно быть положительным числом")
+	errUntil     = errors.New("дата окончания повторений раньше даты задачи")
+	errEnd       = errors.New("условия окончания указываются только для повторяющихся задач")
+	errExc       = errors.New("исключения указываются только для повторяющихся задач")
+	errLast      = errors.New("нельзя исключить последнее повторение задачи")
+	errRuleBoth  = errors.New("правило повторения указывается либо строкой, либо структурой")
+	errMode      = errors.New("неизвестный режим отсчета повторений")
+	errModeRep   = errors.New("режим отсчета указывается только для повторяющихся задач")
+	errModeCount = errors.New("при отсчете от даты выполнения количество повторений указывается в поле count")
+	errPriority  = errors.New("приоритет задачи должен быть числом от 1 до 4")
 )
 
 func New(db *database.DB) *Service {
@@ -33,85 +50,191 @@ func New(db *database.DB) *Service {
 	}
 }
 
-// CheckRepeat проверяет корректность указанного правила повторения.
-func (s *Service) checkRepeat(task models.Task) error {
+// checkRepeat проверяет правило повторения, заданное строкой или структурой, и возвращает его каноническую запись.
+func (s *Service) checkRepeat(task models.Task) (string, error) {
+	if task.Rule != nil {
+		if task.Repeat != "" {
+			return "", fmt.Errorf("%w", errRuleBoth)
+		}
+
+		if err := task.Rule.Validate(); err != nil {
+			return "", fmt.Errorf("%w: %w", errRule, err)
+		}
+
+		return task.Rule.String(), nil
+	}
+
 	if task.Repeat == "" {
+		return "", nil
+	}
+
+	rule, err := date.Parse(task.Repeat)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errRule, err)
+	}
+
+	return rule.String(), nil
+}
+
+// CheckTitle проверяет наличие заголовка.
+func (s *Service) checkTitle(task models.Task) (string, error) {
+	if len(task.Title) == 0 {
+		return "", fmt.Errorf("%w", errTitle)
+	}
+
+	return task.Title, nil
+}
+
+// checkTime проверяет корректность указанного времени задачи.
+func (s *Service) checkTime(task models.Task) error {
+	if task.Time == "" {
 		return nil
 	}
 
-	switch string(task.Repeat[0]) {
-	case "y":
+	if _, err := time.Parse(timeFormat, task.Time); err != nil {
+		return fmt.Errorf("%w", errTime)
+	}
+
+	return nil
+}
+
+// checkPriority проверяет приоритет задачи: пустой или число от 1 до 4.
+func (s *Service) checkPriority(task models.Task) error {
+	if task.Priority == "" {
 		return nil
-	case "d":
-		if err := s.checkDays(task); err != nil {
-			return err
-		}
-	case "w":
-		if err := s.checkWeek(task); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("%w", errRule)
+	}
+
+	if _, err := parsePriority(task.Priority); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// checkDays проверяет корректность указанного правила повторения дней.
-func (s *Service) checkDays(task models.Task) error {
-	slice := strings.Split(task.Repeat, " ")
+// parsePriority разбирает приоритет задачи.
+func parsePriority(value string) (int, error) {
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < models.HighestPriority || priority > models.LowestPriority {
+		return 0, fmt.Errorf("%w", errPriority)
+	}
+
+	return priority, nil
+}
+
+// checkEnd проверяет условия окончания повторений: дату окончания и количество повторений.
+func (s *Service) checkEnd(task models.Task) error {
+	if task.Until == "" && task.Count == "" {
+		return nil
+	}
 
-	if len(slice) != 2 {
-		return fmt.Errorf("%w", errRule)
+	if task.Repeat == "" {
+		return fmt.Errorf("%w", errEnd)
 	}
 
-	days, err := strconv.Atoi(slice[1])
+	if task.Until != "" {
+		if _, err := time.Parse(dateFormat, task.Until); err != nil {
+			return fmt.Errorf("%w", errDate)
+		}
 
-	if err != nil || days < 1 || days > 400 {
-		return fmt.Errorf("%w", errDays)
+		if task.Date > task.Until {
+			return fmt.Errorf("%w", errUntil)
+		}
+	}
+
+	if task.Count != "" {
+		count, err := strconv.Atoi(task.Count)
+		if err != nil || count < 1 {
+			return fmt.Errorf("%w", errCount)
+		}
 	}
 
 	return nil
 }
 
-// checkWeek проверяет корректность указанного правила повторения дней недели.
-func (s *Service) checkWeek(task models.Task) error {
-	slice := strings.Split(task.Repeat, " ")
-
-	if len(slice) != 2 {
-		return fmt.Errorf("%w", errRule)
+// checkAnchor проверяет режим отсчета повторений задачи.
+func (s *Service) checkAnchor(task models.Task) error {
+	switch task.Anchor {
+	case "", models.AnchorCalendar:
+		return nil
+	case models.AnchorCompletion:
+	default:
+		return fmt.Errorf("%w", errMode)
 	}
 
-	weekDays := strings.Split(slice[1], ",")
+	if task.Repeat == "" {
+		return fmt.Errorf("%w", errModeRep)
+	}
 
-	for _, e := range weekDays {
-		wDay, err := strconv.Atoi(e)
-		if err != nil || wDay < 1 || wDay > 7 {
-			return fmt.Errorf("%w", errDays)
+	// COUNT в RRULE отсчитывается от начала серии, которое при таком режиме сдвигается с каждым выполнением.
+	if rule, err := date.Parse(task.Repeat); err == nil && rule.Kind == date.KindRRule {
+		if rrule, err := date.ParseRRule(rule.RRule); err == nil && rrule.Count > 0 {
+			return fmt.Errorf("%w", errModeCount)
 		}
 	}
 
 	return nil
 }
 
-// CheckTitle проверяет наличие заголовка.
-func (s *Service) checkTitle(task models.Task) (string, error) {
-	if len(task.Title) == 0 {
-		return "", fmt.Errorf("%w", errTitle)
+// seriesEnded проверяет, что после выполнения задачи повторений больше не осталось.
+func (s *Service) seriesEnded(task models.Task, nextDate string) bool {
+	return task.Count == "1" || (task.Until != "" && nextDate > task.Until)
+}
+
+// timed проверяет, что правило задачи задает и время повторений (правила h, min и cron).
+func (s *Service) timed(repeat string) bool {
+	rule, err := date.Parse(repeat)
+
+	return err == nil && rule.Timed()
+}
+
+// quota проверяет, что задача повторяется по норме выполнений за период (правило q).
+func (s *Service) quota(repeat string) bool {
+	rule, err := date.Parse(repeat)
+
+	return err == nil && rule.Kind == date.KindQuota
+}
+
+// defaultTime назначает задаче с правилом h, min или cron текущее время, если время не указано:
+// от него отсчитываются повторения.
+func (s *Service) defaultTime(task models.Task, now time.Time) models.Task {
+	if task.Time == "" && s.timed(task.Repeat) {
+		task.Time = now.Format(timeFormat)
 	}
 
-	return task.Title, nil
+	return task
 }
 
-// CheckDate проверяет корректность указанной даты.
-func (s *Service) checkDate(task models.Task) (string, error) {
-	now := time.Now()
+// location возвращает часовой пояс задачи, по умолчанию — часовой пояс сервера.
+func (s *Service) location(task models.Task) (*time.Location, error) {
+	if task.Timezone == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(task.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w", errZone)
+	}
+
+	return loc, nil
+}
+
+// now возвращает текущее время в часовом поясе задачи.
+func (s *Service) now(task models.Task) (time.Time, error) {
+	loc, err := s.location(task)
+	if err != nil {
+		return time.Time{}, err
+	}
 
+	return time.Now().In(loc), nil
+}
+
+// CheckDate проверяет корректность указанной даты.
+func (s *Service) checkDate(task models.Task, now time.Time) (string, error) {
 	if task.Date == "" || task.Date == "today" {
 		return now.Format(dateFormat), nil
 	}
 
-	outDate, err := time.Parse(dateFormat, task.Date)
+	outDate, err := time.ParseInLocation(dateFormat, task.Date, now.Location())
 	if err != nil {
 		return "", fmt.Errorf("%w", errDate)
 	}
@@ -132,14 +255,47 @@ func (s *Service) AddTask(ctx context.Context, task models.Task) (string, error)
 
 	task.Title = titleOfTask
 
-	now := time.Now()
+	if task.Repeat, err = s.checkRepeat(task); err != nil {
+		return "", err
+	}
+
+	task.Rule = nil
 
-	dateOfTask, err := s.addTaskHelper(task, now)
+	if err = s.checkTime(task); err != nil {
+		return "", err
+	}
+
+	if err = s.checkAnchor(task); err != nil {
+		return "", err
+	}
+
+	if err = s.checkPriority(task); err != nil {
+		return "", err
+	}
+
+	if task.Tags, err = s.checkTags(task); err != nil {
+		return "", err
+	}
+
+	if err = s.checkProject(ctx, task); err != nil {
+		return "", err
+	}
+
+	now, err := s.now(task)
 	if err != nil {
 		return "", err
 	}
 
-	task.Date = dateOfTask
+	task = s.defaultTime(task, now)
+
+	task, err = s.addTaskHelper(task, now)
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.checkEnd(task); err != nil {
+		return "", err
+	}
 
 	taskID, err := s.db.AddTask(ctx, task)
 	if err != nil {
@@ -149,46 +305,82 @@ func (s *Service) AddTask(ctx context.Context, task models.Task) (string, error)
 	return taskID, nil
 }
 
-func (s *Service) addTaskHelper(task models.Task, now time.Time) (string, error) {
+func (s *Service) addTaskHelper(task models.Task, now time.Time) (models.Task, error) {
 	if task.Repeat == "" {
-		dateOfTask, err := s.checkDate(task)
+		dateOfTask, err := s.checkDate(task, now)
 		if err != nil {
-			return "", err
+			return models.Task{}, err
 		}
 
-		return dateOfTask, nil
-	}
+		task.Date = dateOfTask
 
-	err := s.checkRepeat(task)
-	if err != nil {
-		return "", err
+		return task, nil
 	}
 
-	dateOfTask, err := s.checkDate(task)
+	dateOfTask, err := s.checkDate(task, now)
 	if err != nil {
-		return "", err
+		return models.Task{}, err
 	}
 
-	if dateOfTask == now.Format(dateFormat) {
+	// Сегодняшняя и будущая даты сохраняются, прошедшая переносится на ближайшее повторение.
+	// Задача с нормой выполнений остается на сегодняшней дате: с нее начинается отсчет периода.
+	if task.Date == "" || task.Date == "today" || task.Date >= now.Format(dateFormat) || s.quota(task.Repeat) {
 		task.Date = dateOfTask
 	} else {
-		nextDate, err := date.NextDate(now, dateOfTask, task.Repeat)
+		nextDate, nextTime, nextRepeat, err := date.NextRepeat(now, task.Date, task.Time, task.Repeat)
 		if err != nil {
-			return "", fmt.Errorf("ошибка вычисления следующей даты: %w", err)
+			return models.Task{}, fmt.Errorf("ошибка вычисления следующей даты: %w", err)
 		}
 		task.Date = nextDate
+		task.Time = nextTime
+		task.Repeat = nextRepeat
 	}
 
-	return task.Date, nil
+	return task, nil
 }
 
-// GetAllTasks получает список ближайших задач.
-func (s *Service) GetAllTasks(ctx context.Context) ([]models.Task, error) {
-	tasks, err := s.db.GetAllTasks(ctx)
+// GetAllTasks получает список ближайших задач. Строка поиска в формате 02.01.2006 отбирает задачи
+// на эту дату, иначе ищется в заголовке и комментарии; приоритеты перечисляются через запятую;
+// задача должна иметь все указанные метки и не иметь меток, указанных с минусом; проект задается ID или inbox.
+func (s *Service) GetAllTasks(ctx context.Context, query models.TaskQuery) ([]models.Task, error) {
+	//nolint:exhaustivestruct
+	filter := models.TaskFilter{Search: strings.TrimSpace(query.Search)}
+
+	if day, err := time.Parse(searchDateFormat, filter.Search); err == nil {
+		filter.Search, filter.Date = "", day.Format(dateFormat)
+	}
+
+	if query.Priority != "" {
+		for _, e := range strings.Split(query.Priority, ",") {
+			priority, err := parsePriority(strings.TrimSpace(e))
+			if err != nil {
+				return nil, err
+			}
+
+			filter.Priorities = append(filter.Priorities, priority)
+		}
+	}
+
+	filter, err := tagFilter(filter, query.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Project, err = projectFilter(query.Project); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.db.GetAllTasks(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения списка задач: %w", err)
 	}
 
+	for i := range tasks {
+		if tasks[i], err = s.withProgress(ctx, tasks[i]); err != nil {
+			return nil, err
+		}
+	}
+
 	return tasks, nil
 }
 
@@ -210,7 +402,7 @@ func (s *Service) GetTaskID(ctx context.Context, id string) (models.Task, error)
 		return models.Task{}, fmt.Errorf("ошибка получения задачи из списка: %w", err)
 	}
 
-	return taskID, nil
+	return s.withProgress(ctx, taskID)
 }
 
 // UpdateTask редактирует задачу.
@@ -226,32 +418,81 @@ func (s *Service) UpdateTask(ctx context.Context, task models.Task) (models.Task
 
 	task.Title = titleOfTask
 
-	now := time.Now()
+	if task.Repeat, err = s.checkRepeat(task); err != nil {
+		return models.Task{}, err
+	}
+
+	task.Rule = nil
+
+	if err = s.checkTime(task); err != nil {
+		return models.Task{}, err
+	}
+
+	if err = s.checkAnchor(task); err != nil {
+		return models.Task{}, err
+	}
+
+	if err = s.checkPriority(task); err != nil {
+		return models.Task{}, err
+	}
+
+	if task.Tags, err = s.checkTags(task); err != nil {
+		return models.Task{}, err
+	}
+
+	if err = s.checkProject(ctx, task); err != nil {
+		return models.Task{}, err
+	}
+
+	now, err := s.now(task)
+	if err != nil {
+		return models.Task{}, err
+	}
 
 	if task.Date == "" {
 		task.Date = now.Format(dateFormat)
 	}
 
-	dateOfTask, err := time.Parse(dateFormat, task.Date)
+	task = s.defaultTime(task, now)
+
+	layout, value := dateFormat, task.Date
+
+	// Для правил h, min и cron задача считается пропущенной с учетом времени.
+	if s.timed(task.Repeat) {
+		layout, value = dateFormat+timeFormat, task.Date+task.Time
+	}
+
+	dateOfTask, err := time.ParseInLocation(layout, value, now.Location())
 	if err != nil {
 		return models.Task{}, fmt.Errorf("%w", errDate)
 	}
 
-	if dateOfTask.Before(now) {
+	// При отсчете от даты выполнения и для нормы выполнений пропущенная задача не переносится по календарю,
+	// а ждет выполнения сегодня.
+	if dateOfTask.Before(now) && (task.Anchor == models.AnchorCompletion || s.quota(task.Repeat)) {
+		task.Date = now.Format(dateFormat)
+	} else if dateOfTask.Before(now) {
 		if task.Repeat == "" {
 			task.Date = now.Format(dateFormat)
 		}
 
-		nextDate, err := date.NextDate(now, task.Date, task.Repeat)
+		exceptions, err := s.exceptions(ctx, task.ID)
+		if err != nil {
+			return models.Task{}, err
+		}
+
+		nextDate, nextTime, nextRepeat, err := date.NextRepeat(now, task.Date, task.Time, task.Repeat, exceptions...)
 		if err != nil {
 			return models.Task{}, fmt.Errorf("ошибка вычисления следующей даты: %w", err)
 		}
 
 		task.Date = nextDate
+		task.Time = nextTime
+		task.Repeat = nextRepeat
 	}
 
-	if err := s.checkRepeat(task); err != nil {
-		return models.Task{}, fmt.Errorf("%w", errRule)
+	if err := s.checkEnd(task); err != nil {
+		return models.Task{}, err
 	}
 
 	updatedTask, err := s.db.UpdateTask(ctx, task)
@@ -262,8 +503,8 @@ func (s *Service) UpdateTask(ctx context.Context, task models.Task) (models.Task
 	return updatedTask, nil
 }
 
-// TaskDone делает задачу выполненной.
-func (s *Service) TaskDone(ctx context.Context, id string) error {
+// TaskDone делает задачу выполненной и записывает выполнение в историю с необязательной заметкой.
+func (s *Service) TaskDone(ctx context.Context, id string, note string) error {
 	if id == "" {
 		return fmt.Errorf("%w", errID)
 	}
@@ -280,20 +521,73 @@ func (s *Service) TaskDone(ctx context.Context, id string) error {
 		return fmt.Errorf("ошибка получения задачи из списка: %w", err)
 	}
 
+	now, err := s.now(task)
+	if err != nil {
+		return err
+	}
+
+	//nolint:exhaustivestruct
+	completion := models.Completion{
+		TaskID: task.ID,
+		Date:   task.Date,
+		Time:   task.Time,
+		DoneAt: now.Format(time.RFC3339),
+		Note:   note,
+	}
+
 	switch task.Repeat {
 	case "":
-		if err = s.db.DeleteTaskID(ctx, int64(idInt)); err != nil {
+		if err = s.db.FinishTask(ctx, int64(idInt), completion); err != nil {
 			return fmt.Errorf("ошибка удаления задачи: %w", err)
 		}
 	default:
-		now := time.Now()
+		if s.quota(task.Repeat) {
+			return s.quotaDone(ctx, task, now, completion)
+		}
+
+		exceptions, err := s.db.GetExceptions(ctx, int64(idInt))
+		if err != nil {
+			return fmt.Errorf("ошибка получения исключений: %w", err)
+		}
+
+		// При отсчете от даты выполнения следующая дата считается от сегодняшнего дня
+		// (для правил h, min и cron — от текущего времени).
+		from, fromTime := task.Date, task.Time
+		if task.Anchor == models.AnchorCompletion {
+			from = now.Format(dateFormat)
+
+			if s.timed(task.Repeat) {
+				fromTime = now.Format(timeFormat)
+			}
+		}
+
+		nextDate, nextTime, nextRepeat, err := date.NextRepeat(now, from, fromTime, task.Repeat, exceptions...)
+		if errors.Is(err, date.ErrSeriesEnd) || (err == nil && s.seriesEnded(task, nextDate)) {
+			if err = s.db.FinishTask(ctx, int64(idInt), completion); err != nil {
+				return fmt.Errorf("ошибка удаления задачи: %w", err)
+			}
+
+			return nil
+		}
 
-		nextDate, err := date.NextDate(now, task.Date, task.Repeat)
 		if err != nil {
 			return fmt.Errorf("ошибка вычисления следующей даты: %w", err)
 		}
 
-		if err = s.db.TaskDone(ctx, nextDate, int64(idInt)); err != nil {
+		task.Date = nextDate
+		task.Time = nextTime
+		task.Repeat = nextRepeat
+
+		if task.Count != "" {
+			count, err := strconv.Atoi(task.Count)
+			if err != nil {
+				return fmt.Errorf("%w", errCount)
+			}
+
+			task.Count = strconv.Itoa(count - 1)
+		}
+
+		if err = s.db.TaskDone(ctx, task, &completion); err != nil {
 			return fmt.Errorf("ошибка выполнения задачи: %w", err)
 		}
 	}
@@ -301,7 +595,115 @@ func (s *Service) TaskDone(ctx context.Context, id string) error {
 	return nil
 }
 
-// DeleteTask удаляет задачу.
+// GetHistory получает историю выполнений задачи, в том числе уже удаленной.
+func (s *Service) GetHistory(ctx context.Context, id string) ([]models.Completion, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w", errID)
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	completions, err := s.db.GetCompletions(ctx, int64(idInt))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории выполнений: %w", err)
+	}
+
+	return completions, nil
+}
+
+// quotaProgress возвращает дату, на которую засчитывается выполнение задачи с нормой выполнений,
+// и число выполнений в ее периоде по истории выполнений.
+func (s *Service) quotaProgress(ctx context.Context, task models.Task, now time.Time) (string, int, error) {
+	current, start, end, err := date.QuotaPeriod(now, task.Date, task.Repeat)
+	if err != nil {
+		return "", 0, fmt.Errorf("ошибка вычисления периода нормы: %w", err)
+	}
+
+	idInt, err := strconv.Atoi(task.ID)
+	if err != nil {
+		return "", 0, fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	done, err := s.db.CountCompletions(ctx, int64(idInt), start, end)
+	if err != nil {
+		return "", 0, fmt.Errorf("ошибка получения числа выполнений: %w", err)
+	}
+
+	return current, done, nil
+}
+
+// withProgress заполняет число выполнений в текущем периоде для задач с нормой выполнений.
+func (s *Service) withProgress(ctx context.Context, task models.Task) (models.Task, error) {
+	if !s.quota(task.Repeat) {
+		return task, nil
+	}
+
+	now, err := s.now(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	_, done, err := s.quotaProgress(ctx, task, now)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task.Progress = strconv.Itoa(done)
+
+	return task, nil
+}
+
+// quotaDone засчитывает выполнение задачи с нормой выполнений за период. Выполнение записывается
+// в историю на дату, к периоду которой оно относится.
+// Условия окончания повторений (count и until) проверяются, когда норма периода выполнена.
+func (s *Service) quotaDone(ctx context.Context, task models.Task, now time.Time, completion models.Completion) error {
+	current, done, err := s.quotaProgress(ctx, task, now)
+	if err != nil {
+		return err
+	}
+
+	nextDate, periodDone, err := date.QuotaDone(now, task.Date, task.Repeat, done)
+	if err != nil {
+		return fmt.Errorf("ошибка вычисления следующей даты: %w", err)
+	}
+
+	completion.Date = current
+
+	if periodDone && s.seriesEnded(task, nextDate) {
+		idInt, err := strconv.Atoi(task.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка конвертации ID: %w", err)
+		}
+
+		if err = s.db.FinishTask(ctx, int64(idInt), completion); err != nil {
+			return fmt.Errorf("ошибка удаления задачи: %w", err)
+		}
+
+		return nil
+	}
+
+	task.Date = nextDate
+
+	if periodDone && task.Count != "" {
+		count, err := strconv.Atoi(task.Count)
+		if err != nil {
+			return fmt.Errorf("%w", errCount)
+		}
+
+		task.Count = strconv.Itoa(count - 1)
+	}
+
+	if err = s.db.TaskDone(ctx, task, &completion); err != nil {
+		return fmt.Errorf("ошибка выполнения задачи: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTask переносит задачу в корзину.
 func (s *Service) DeleteTask(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("%w", errID)
@@ -318,3 +720,164 @@ func (s *Service) DeleteTask(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// GetTrash получает список задач из корзины.
+func (s *Service) GetTrash(ctx context.Context) ([]models.Task, error) {
+	tasks, err := s.db.GetTrash(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения корзины: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины.
+func (s *Service) RestoreTask(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w", errID)
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	if err = s.db.RestoreTask(ctx, int64(idInt)); err != nil {
+		return fmt.Errorf("ошибка восстановления задачи: %w", err)
+	}
+
+	return nil
+}
+
+// RunPurge раз в interval окончательно удаляет задачи, пролежавшие в корзине дольше retention,
+// пока не отменен контекст.
+func (s *Service) RunPurge(ctx context.Context, retention time.Duration, interval time.Duration) {
+	t := time.NewTicker(interval)
+
+	defer t.Stop()
+
+	for {
+		purged, err := s.db.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logrus.Warnf("ошибка очистки корзины: %v", err)
+		} else if purged > 0 {
+			logrus.Infof("из корзины удалено задач: %d", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// exceptions получает даты, исключенные из повторений задачи.
+func (s *Service) exceptions(ctx context.Context, id string) ([]string, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	exceptions, err := s.db.GetExceptions(ctx, int64(idInt))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+// GetExceptions получает даты, исключенные из повторений задачи.
+func (s *Service) GetExceptions(ctx context.Context, id string) ([]string, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w", errID)
+	}
+
+	return s.exceptions(ctx, id)
+}
+
+// AddException исключает дату из повторений задачи.
+// Если задача назначена как раз на эту дату, она переносится на следующее повторение.
+func (s *Service) AddException(ctx context.Context, id string, dateOfException string) error {
+	task, err := s.GetTaskID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if task.Repeat == "" {
+		return fmt.Errorf("%w", errExc)
+	}
+
+	if _, err = time.Parse(dateFormat, dateOfException); err != nil {
+		return fmt.Errorf("%w", errDate)
+	}
+
+	exceptions, err := s.exceptions(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	moved := task.Date == dateOfException
+
+	if moved {
+		now, err := s.now(task)
+		if err != nil {
+			return err
+		}
+
+		nextDate, nextTime, nextRepeat, err := date.NextRepeat(now, task.Date, task.Time, task.Repeat,
+			append(exceptions, dateOfException)...)
+		if errors.Is(err, date.ErrSeriesEnd) || (err == nil && task.Until != "" && nextDate > task.Until) {
+			return fmt.Errorf("%w", errLast)
+		}
+
+		if err != nil {
+			return fmt.Errorf("ошибка вычисления следующей даты: %w", err)
+		}
+
+		task.Date = nextDate
+		task.Time = nextTime
+		task.Repeat = nextRepeat
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	if err = s.db.AddException(ctx, int64(idInt), dateOfException); err != nil {
+		return fmt.Errorf("ошибка добавления исключения: %w", err)
+	}
+
+	if !moved {
+		return nil
+	}
+
+	if err = s.db.TaskDone(ctx, task, nil); err != nil {
+		return fmt.Errorf("ошибка переноса задачи: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteException возвращает дату в повторения задачи.
+func (s *Service) DeleteException(ctx context.Context, id string, dateOfException string) error {
+	if _, err := s.GetTaskID(ctx, id); err != nil {
+		return err
+	}
+
+	if _, err := time.Parse(dateFormat, dateOfException); err != nil {
+		return fmt.Errorf("%w", errDate)
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("ошибка конвертации ID: %w", err)
+	}
+
+	if err = s.db.DeleteException(ctx, int64(idInt), dateOfException); err != nil {
+		return fmt.Errorf("ошибка удаления исключения: %w", err)
+	}
+
+	return nil
+}
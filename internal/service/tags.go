@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Memonagi/go_final_project/internal/models"
+)
+
+const (
+	// maxTagLength наибольшая длина названия метки.
+	maxTagLength = 32
+	// excludeTag отмечает в фильтре метку, задачи с которой исключаются из списка.
+	excludeTag = "-"
+)
+
+var errTag = errors.New("метка может содержать только буквы, цифры, дефис и подчеркивание, " +
+	"не должна начинаться с дефиса и быть длиннее 32 символов")
+
+// normalizeTag приводит название метки к нижнему регистру, отбрасывает необязательный # в начале
+// и проверяет допустимые символы.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+
+	if tag == "" || strings.HasPrefix(tag, excludeTag) || utf8.RuneCountInString(tag) > maxTagLength {
+		return "", fmt.Errorf("%w: %q", errTag, tag)
+	}
+
+	if strings.ContainsFunc(tag, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	}) {
+		return "", fmt.Errorf("%w: %q", errTag, tag)
+	}
+
+	return tag, nil
+}
+
+// checkTags проверяет метки задачи и возвращает их без повторов.
+func (s *Service) checkTags(task models.Task) ([]string, error) {
+	var tags []string
+
+	for _, e := range task.Tags {
+		tag, err := normalizeTag(e)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+// tagFilter разбирает метки из параметров запроса списка задач на нужные и исключаемые.
+func tagFilter(filter models.TaskFilter, tags []string) (models.TaskFilter, error) {
+	for _, e := range tags {
+		exclude := strings.HasPrefix(e, excludeTag)
+
+		tag, err := normalizeTag(strings.TrimPrefix(e, excludeTag))
+		if err != nil {
+			return models.TaskFilter{}, err
+		}
+
+		if exclude {
+			filter.ExcludedTags = append(filter.ExcludedTags, tag)
+		} else {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+
+	return filter, nil
+}
+
+// GetTags получает список меток с числом задач, начиная с самых используемых.
+func (s *Service) GetTags(ctx context.Context) ([]models.Tag, error) {
+	tags, err := s.db.GetTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения меток: %w", err)
+	}
+
+	return tags, nil
+}
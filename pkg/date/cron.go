@@ -0,0 +1,250 @@
+package date
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cronFields = 5
+
+var errCronFields = errors.New("в выражении cron должно быть 5 полей: минуты, часы, день месяца, месяц, день недели")
+
+// CronFieldError ошибка в отдельном поле выражения cron.
+type CronFieldError struct {
+	// Position номер поля, начиная с 1.
+	Position int
+	// Name название поля.
+	Name string
+	// Value значение поля.
+	Value string
+}
+
+func (e *CronFieldError) Error() string {
+	return fmt.Sprintf("неверное поле %d (%s) в выражении cron: «%s»", e.Position, e.Name, e.Value)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, errRule).
+func (e *CronFieldError) Unwrap() error {
+	return errRule
+}
+
+// cronField описание поля выражения cron.
+type cronField struct {
+	name     string
+	minValue int
+	maxValue int
+	names    map[string]int
+}
+
+var cronFieldsList = [cronFields]cronField{
+	{name: "минуты", minValue: 0, maxValue: 59},
+	{name: "часы", minValue: 0, maxValue: 23},
+	{name: "день месяца", minValue: 1, maxValue: 31},
+	{name: "месяц", minValue: 1, maxValue: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}},
+	// 0 и 7 — воскресенье.
+	{name: "день недели", minValue: 0, maxValue: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}},
+}
+
+// cronSchedule разобранное выражение cron: множества подходящих значений каждого поля.
+type cronSchedule struct {
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+	// anyDay и anyWeekday — поля дня месяца и дня недели заданы звездочкой.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// parseCron разбирает выражение cron из пяти полей, например «0 9 * * 1-5».
+func parseCron(expression string) (cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != cronFields {
+		return cronSchedule{}, fmt.Errorf("%w", errCronFields)
+	}
+
+	var schedule cronSchedule
+
+	for i, value := range fields {
+		values, err := cronFieldsList[i].parse(value)
+		if err != nil {
+			return cronSchedule{}, &CronFieldError{Position: i + 1, Name: cronFieldsList[i].name, Value: value}
+		}
+
+		for _, e := range values {
+			switch i {
+			case 0:
+				schedule.minutes[e] = true
+			case 1:
+				schedule.hours[e] = true
+			case 2:
+				schedule.days[e] = true
+			case 3:
+				schedule.months[e] = true
+			default:
+				schedule.weekdays[e%daysInWeek] = true
+			}
+		}
+	}
+
+	schedule.anyDay = fields[2] == "*"
+	schedule.anyWeekday = fields[4] == "*"
+
+	// Если день недели не задан, дата определяется только днем месяца и месяцем:
+	// выражение «0 9 31 2 *» не срабатывает ни в одном году.
+	if schedule.anyWeekday && !schedule.hasMonthDay() {
+		return cronSchedule{}, fmt.Errorf("%w", errNoDates)
+	}
+
+	return schedule, nil
+}
+
+// hasMonthDay сообщает, есть ли хотя бы один из указанных дней месяца в одном из указанных месяцев.
+func (c cronSchedule) hasMonthDay() bool {
+	for month := minMonth; month <= maxMonth; month++ {
+		if !c.months[month] {
+			continue
+		}
+
+		for day := minMDay; day <= monthDays(month); day++ {
+			if c.days[day] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// parse разбирает значение поля: *, число, диапазон a-b и шаг */n или a-b/n, а также списки через запятую.
+func (f cronField) parse(value string) ([]int, error) {
+	var values []int
+
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("%w", errRule)
+			}
+		}
+
+		from, to := f.minValue, f.maxValue
+
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+
+			from, err = f.value(first)
+			if err != nil {
+				return nil, err
+			}
+
+			to = from
+
+			switch {
+			case isRange:
+				if to, err = f.value(last); err != nil {
+					return nil, err
+				}
+			case hasStep:
+				// Запись a/n означает значения от a до конца диапазона с шагом n.
+				to = f.maxValue
+			}
+
+			if from > to {
+				return nil, fmt.Errorf("%w", errRule)
+			}
+		}
+
+		for e := from; e <= to; e += step {
+			values = append(values, e)
+		}
+	}
+
+	return values, nil
+}
+
+// value разбирает одно значение поля: число или название месяца либо дня недели.
+func (f cronField) value(value string) (int, error) {
+	if num, ok := f.names[strings.ToUpper(value)]; ok {
+		return num, nil
+	}
+
+	num, err := strconv.Atoi(value)
+	if err != nil || num < f.minValue || num > f.maxValue {
+		return 0, fmt.Errorf("%w", errRule)
+	}
+
+	return num, nil
+}
+
+// matchDay проверяет день. Если ограничены и день месяца, и день недели, достаточно совпадения одного из них.
+func (c cronSchedule) matchDay(date time.Time) bool {
+	if !c.months[date.Month()] {
+		return false
+	}
+
+	day, weekday := c.days[date.Day()], c.weekdays[date.Weekday()]
+
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// cronRule вычисляет следующее повторение по выражению cron после даты и времени задачи и текущего времени.
+func cronRule(now time.Time, date time.Time, expression string) (time.Time, error) {
+	schedule, err := parseCron(expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	after := date
+	if now.After(after) {
+		after = now
+	}
+
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, after.Location())
+
+	for end := day.AddDate(searchYears, 0, 0); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !schedule.matchDay(day) {
+			continue
+		}
+
+		for hour := range schedule.hours {
+			for minute := range schedule.minutes {
+				if !schedule.hours[hour] || !schedule.minutes[minute] {
+					continue
+				}
+
+				next := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+				if next.After(after) {
+					return next, nil
+				}
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w", errRule)
+}
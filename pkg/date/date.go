@@ -0,0 +1,321 @@
+// Package date разбирает правила повторения задач и вычисляет даты повторений.
+// Пакет не зависит от остального сервиса и может использоваться другими модулями.
+package date
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	errDate  = errors.New("неверный формат даты")
+	errDays  = errors.New("указано неверное количество дней")
+	errRule  = errors.New("неверный формат правила")
+	errCount = errors.New("неверное количество дат")
+	errTime  = errors.New("неверный формат времени")
+	// errNoDates правило записано верно, но ни одна дата календаря под него не подходит.
+	errNoDates = errors.New("правило не задает ни одной существующей даты")
+)
+
+const (
+	minDays    = 1
+	maxDays    = 400
+	minWDay    = 1
+	maxWDay    = 7
+	minMDay    = 1
+	maxMDay    = 31
+	minNegMDay = -2
+	minMonth   = 1
+	maxMonth   = 12
+	maxWeekNum = 5
+	maxHours   = 24
+	maxMinutes = 24 * 60
+	// shiftModifier переносит вычисленную дату с выходного или праздника на ближайший рабочий день.
+	shiftModifier = "shift"
+	// searchYears ограничивает поиск подходящей даты (29 февраля может не встречаться до 8 лет).
+	searchYears  = 8
+	dateFormat   = "20060102"
+	timeFormat   = "15:04"
+	secondsInDay = 24 * 60 * 60
+	// MaxOccurrences наибольшее количество дат, которое можно получить за один вызов NextDates.
+	MaxOccurrences = 100
+)
+
+// NextDate функция для определения следующей даты в соответствии с правилом.
+// Даты из except (в формате 20060102) пропускаются.
+func NextDate(now time.Time, dateString string, repeat string, except ...string) (string, error) {
+	next, _, err := NextDateTime(now, dateString, "", repeat, except...)
+
+	return next, err
+}
+
+// NextDateTime определяет следующую дату и время задачи. Время (в формате 15:04, может быть пустым)
+// учитывается правилами h, min и cron, для остальных правил оно возвращается без изменений.
+func NextDateTime(now time.Time, dateString string, timeString string, repeat string,
+	except ...string,
+) (string, string, error) {
+	if repeat == "" {
+		return "", "", errRule
+	}
+
+	// Дата задачи считается в том же часовом поясе, что и текущее время.
+	date, err := time.ParseInLocation(dateFormat, dateString, now.Location())
+	if err != nil {
+		return "", "", errDate
+	}
+
+	rule, err := Parse(repeat)
+	if err != nil {
+		return "", "", err
+	}
+
+	if rule.Timed() && timeString != "" {
+		clock, err := time.Parse(timeFormat, timeString)
+		if err != nil {
+			return "", "", fmt.Errorf("%w", errTime)
+		}
+
+		date = time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, date.Location())
+	}
+
+	next, err := rule.next(now, date)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Исключенный день пропускается целиком, поэтому следующий поиск начинается с конца этого дня.
+	for i := 0; i < len(except) && slices.Contains(except, next.Format(dateFormat)); i++ {
+		next, err = rule.next(endOfDay(next), date)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	if slices.Contains(except, next.Format(dateFormat)) {
+		return "", "", fmt.Errorf("%w", errRule)
+	}
+
+	if rule.Timed() {
+		timeString = next.Format(timeFormat)
+	}
+
+	return next.Format(dateFormat), timeString, nil
+}
+
+// NextDates возвращает до count следующих дат по правилу, не позднее until (если указана).
+// Для правил h, min и cron к дате добавляется время: 20060102 15:04.
+func NextDates(now time.Time, dateString string, timeString string, repeat string, count int, until string,
+	except ...string,
+) ([]string, error) {
+	if count < 1 || count > MaxOccurrences {
+		return nil, fmt.Errorf("%w", errCount)
+	}
+
+	if until != "" {
+		if _, err := time.Parse(dateFormat, until); err != nil {
+			return nil, fmt.Errorf("%w", errDate)
+		}
+	}
+
+	rule, err := Parse(repeat)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, count)
+
+	for len(dates) < count {
+		next, nextTime, err := NextDateTime(now, dateString, timeString, repeat, except...)
+		if errors.Is(err, ErrSeriesEnd) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if until != "" && next > until {
+			break
+		}
+
+		layout, value := dateFormat, next
+
+		if rule.Timed() {
+			layout, value = dateFormat+" "+timeFormat, next+" "+nextTime
+		}
+
+		dates = append(dates, value)
+
+		now, err = time.ParseInLocation(layout, value, now.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%w", errDate)
+		}
+	}
+
+	return dates, nil
+}
+
+// endOfDay возвращает последнее мгновение дня указанной даты.
+func endOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, date.Location()).Add(-time.Nanosecond)
+}
+
+// dayRule проверяет правило повторения дней.
+// Число пропущенных интервалов вычисляется сразу, без перебора дат от даты задачи.
+func dayRule(now time.Time, date time.Time, days int) (time.Time, error) {
+	periods := 1
+
+	if passed := daysBetween(date, now); passed >= 0 {
+		periods = passed/days + 1
+	}
+
+	date = date.AddDate(0, 0, periods*days)
+
+	// Полночь может не существовать из-за перехода на летнее время, тогда нужен еще один интервал.
+	for !date.After(now) {
+		date = date.AddDate(0, 0, days)
+	}
+
+	return date, nil
+}
+
+// yearRule проверяет правило повторения лет.
+func yearRule(now time.Time, date time.Time) (time.Time, error) {
+	years := max(now.Year()-date.Year(), 1)
+
+	next := addYears(date, years)
+	if !next.After(now) {
+		next = addYears(date, years+1)
+	}
+
+	return next, nil
+}
+
+// addYears сдвигает дату на указанное число лет. 29 февраля переходит на 1 марта,
+// как при последовательном прибавлении года: в следующем году такой даты нет.
+func addYears(date time.Time, years int) time.Time {
+	if date.Month() == time.February && date.Day() == 29 {
+		return time.Date(date.Year()+years, time.March, 1, 0, 0, 0, 0, date.Location())
+	}
+
+	return date.AddDate(years, 0, 0)
+}
+
+// subDailyRule проверяет правило повторения через несколько часов или минут.
+// Отсчет ведется от даты и времени задачи, пропущенные интервалы вычисляются сразу.
+func subDailyRule(now time.Time, start time.Time, step time.Duration) (time.Time, error) {
+	seconds := int64(step / time.Second)
+	periods := int64(1)
+
+	if passed := now.Unix() - start.Unix(); passed >= 0 {
+		periods = passed/seconds + 1
+	}
+
+	return time.Unix(start.Unix()+periods*seconds, 0).In(start.Location()), nil
+}
+
+// daysBetween возвращает число календарных дней от одной даты до другой.
+func daysBetween(from time.Time, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	// time.Duration не вмещает разницу больше 292 лет, поэтому считаем в секундах.
+	return int((toDay.Unix() - fromDay.Unix()) / secondsInDay)
+}
+
+// weekRule проверяет правило повторения дней недели (1 — понедельник, 7 — воскресенье).
+func weekRule(now time.Time, date time.Time, week []int) (time.Time, error) {
+	days := make(map[time.Weekday]bool)
+
+	for _, e := range week {
+		days[time.Weekday(e%maxWDay)] = true
+	}
+
+	return nextMatch(now, date, func(date time.Time) bool {
+		return days[date.Weekday()]
+	})
+}
+
+// monthRule проверяет правило повторения дней месяца.
+// Формат: m <дни через запятую> [<месяцы через запятую>], где -1 — последний день месяца, -2 — предпоследний.
+func monthRule(now time.Time, date time.Time, mDays []int, mList []int) (time.Time, error) {
+	days := make(map[int]bool)
+
+	for _, e := range mDays {
+		days[e] = true
+	}
+
+	months := monthsFilter(mList)
+
+	return nextMatch(now, date, func(date time.Time) bool {
+		if len(months) > 0 && !months[date.Month()] {
+			return false
+		}
+
+		return days[date.Day()] || days[date.Day()-lastDay(date)-1]
+	})
+}
+
+// weekMonthRule проверяет правило повторения n-го дня недели месяца.
+// Формат: wm <номер>:<день недели>[,<номер>:<день недели>] [<месяцы через запятую>], где номер -1 — последний.
+func weekMonthRule(now time.Time, date time.Time, nums []WeekNum, mList []int) (time.Time, error) {
+	months := monthsFilter(mList)
+
+	return nextMatch(now, date, func(date time.Time) bool {
+		if len(months) > 0 && !months[date.Month()] {
+			return false
+		}
+
+		for _, e := range nums {
+			if date.Weekday() != time.Weekday(e.Weekday%maxWDay) {
+				continue
+			}
+
+			if (e.Num == -1 && date.Day()+maxWDay > lastDay(date)) || e.Num == (date.Day()-1)/maxWDay+1 {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// monthsFilter возвращает множество месяцев правила.
+func monthsFilter(list []int) map[time.Month]bool {
+	months := make(map[time.Month]bool)
+
+	for _, e := range list {
+		months[time.Month(e)] = true
+	}
+
+	return months
+}
+
+// lastDay возвращает номер последнего дня месяца указанной даты.
+func lastDay(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+}
+
+// monthDays возвращает наибольшее число дней месяца с учетом високосного года (29 для февраля).
+func monthDays(month int) int {
+	return lastDay(time.Date(2000, time.Month(month), 1, 0, 0, 0, 0, time.UTC))
+}
+
+// nextMatch ищет ближайший день после даты задачи и текущей даты, подходящий под условие.
+func nextMatch(now time.Time, date time.Time, match func(date time.Time) bool) (time.Time, error) {
+	date = date.AddDate(0, 0, 1)
+
+	if date.Before(now) {
+		date = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())
+	}
+
+	for end := date.AddDate(searchYears, 0, 0); date.Before(end); date = date.AddDate(0, 0, 1) {
+		if match(date) && date.After(now) {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w", errRule)
+}
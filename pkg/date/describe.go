@@ -0,0 +1,453 @@
+package date
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Языки описаний правил повторения.
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// Род дня недели для согласования порядковых числительных.
+const (
+	masculine = iota
+	feminine
+	neuter
+)
+
+// ruWeekday формы дня недели: винительный падеж, дательный падеж множественного числа и род.
+type ruWeekday struct {
+	acc    string
+	datPl  string
+	gender int
+}
+
+var ruWeekdays = map[time.Weekday]ruWeekday{
+	time.Monday:    {"понедельник", "понедельникам", masculine},
+	time.Tuesday:   {"вторник", "вторникам", masculine},
+	time.Wednesday: {"среду", "средам", feminine},
+	time.Thursday:  {"четверг", "четвергам", masculine},
+	time.Friday:    {"пятницу", "пятницам", feminine},
+	time.Saturday:  {"субботу", "субботам", feminine},
+	time.Sunday:    {"воскресенье", "воскресеньям", neuter},
+}
+
+// ruOrdinals порядковые числительные в винительном падеже: с первого по пятый и последний.
+var ruOrdinals = map[int][]string{
+	masculine: {"первый", "второй", "третий", "четвертый", "пятый", "последний"},
+	feminine:  {"первую", "вторую", "третью", "четвертую", "пятую", "последнюю"},
+	neuter:    {"первое", "второе", "третье", "четвертое", "пятое", "последнее"},
+}
+
+var enOrdinals = []string{"first", "second", "third", "fourth", "fifth"}
+
+var ruMonthsGen = []string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+var ruMonthsPrep = []string{
+	"январе", "феврале", "марте", "апреле", "мае", "июне",
+	"июле", "августе", "сентябре", "октябре", "ноябре", "декабре",
+}
+
+// Describe возвращает понятное описание правила повторения на русском (LangRU) или английском (LangEN) языке.
+func Describe(repeat string, lang string) (string, error) {
+	rule, err := Parse(repeat)
+	if err != nil {
+		return "", err
+	}
+
+	return rule.Describe(lang)
+}
+
+// Describe возвращает понятное описание правила на русском (LangRU) или английском (LangEN) языке.
+func (r Rule) Describe(lang string) (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+
+	en := lang == LangEN
+	description := r.describe(en)
+
+	if r.Shift {
+		description += pick(en, ", moved to the next business day", ", с переносом на рабочий день")
+	}
+
+	return description, nil
+}
+
+// describe описывает проверенное правило без модификатора переноса.
+func (r Rule) describe(en bool) string {
+	switch r.Kind {
+	case KindDay:
+		return every(r.Interval, en, "day", "days", "каждый день", "каждый %d день", "каждые %d дня", "каждые %d дней")
+	case KindBusinessDay:
+		return every(r.Interval, en, "business day", "business days", "каждый рабочий день",
+			"каждый %d рабочий день", "каждые %d рабочих дня", "каждые %d рабочих дней")
+	case KindHour:
+		return every(r.Interval, en, "hour", "hours", "каждый час", "каждый %d час", "каждые %d часа", "каждые %d часов")
+	case KindMinute:
+		return every(r.Interval, en, "minute", "minutes", "каждую минуту",
+			"каждую %d минуту", "каждые %d минуты", "каждые %d минут")
+	case KindYear:
+		if len(r.YearDates) > 0 {
+			return describeYearDates(r.YearDates, r.Feb29, en)
+		}
+
+		if r.Feb29 != "" && r.Feb29 != Feb29Mar1 {
+			return pick(en, "every year", "каждый год") + describeFeb29(r.Feb29, en)
+		}
+
+		return pick(en, "every year", "каждый год")
+	case KindWeek:
+		items := make([]string, 0, len(r.Weekdays))
+
+		for _, e := range r.Weekdays {
+			items = append(items, weekdayName(time.Weekday(e%maxWDay), en))
+		}
+
+		return pick(en, "every ", "по ") + joinList(items, en)
+	case KindMonth:
+		if en {
+			return "on the " + monthDaysEN(r.MonthDays) + " of " + monthsList(r.Months, en)
+		}
+
+		return monthDaysRU(r.MonthDays) + " " + monthsList(r.Months, en)
+	case KindWeekMonth:
+		items := make([]string, 0, len(r.WeekNums))
+
+		for _, e := range r.WeekNums {
+			items = append(items, weekNumName(e.Num, time.Weekday(e.Weekday%maxWDay), en))
+		}
+
+		if en {
+			return "on " + joinList(items, en) + " of " + monthsList(r.Months, en)
+		}
+
+		return joinList(items, en) + " " + monthsList(r.Months, en)
+	case KindCron:
+		return describeCron(r.Cron, en)
+	case KindQuota:
+		periods := map[string][]string{
+			PeriodDay:   {"day", "день"},
+			PeriodWeek:  {"week", "неделю"},
+			PeriodMonth: {"month", "месяц"},
+			PeriodYear:  {"year", "год"},
+		}
+
+		return pick(en, countEN(r.Interval)+" a "+periods[r.Period][0],
+			pluralRU(r.Interval, "%d раз", "%d раза", "%d раз")+" в "+periods[r.Period][1])
+	default:
+		rule, _ := ParseRRule(r.RRule)
+
+		return rule.describe(en)
+	}
+}
+
+// describeCron описывает выражение cron. Расписание с одним временем в день описывается словами,
+// остальные расписания — самим выражением.
+func describeCron(expression string, en bool) string {
+	fields := strings.Fields(expression)
+	generic := pick(en, "on cron schedule “"+strings.Join(fields, " ")+"”",
+		"по расписанию cron «"+strings.Join(fields, " ")+"»")
+
+	minute, errMinute := strconv.Atoi(fields[0])
+	hour, errHour := strconv.Atoi(fields[1])
+
+	schedule, err := parseCron(expression)
+	if err != nil || errMinute != nil || errHour != nil || fields[3] != "*" {
+		return generic
+	}
+
+	at := fmt.Sprintf(pick(en, "at %02d:%02d", "в %02d:%02d"), hour, minute)
+
+	switch {
+	case schedule.anyDay && schedule.anyWeekday:
+		return pick(en, "every day ", "каждый день ") + at
+	case schedule.anyDay:
+		var items []string
+
+		for i := 1; i <= daysInWeek; i++ {
+			if day := time.Weekday(i % daysInWeek); schedule.weekdays[day] {
+				items = append(items, weekdayName(day, en))
+			}
+		}
+
+		return pick(en, "every ", "по ") + joinList(items, en) + " " + at
+	case schedule.anyWeekday:
+		var days []int
+
+		for i, ok := range schedule.days {
+			if ok {
+				days = append(days, i)
+			}
+		}
+
+		if en {
+			return "on the " + monthDaysEN(days) + " of every month " + at
+		}
+
+		return monthDaysRU(days) + " каждого месяца " + at
+	default:
+		return generic
+	}
+}
+
+// describe описывает правило RRULE.
+func (r RRule) describe(en bool) string {
+	var base string
+
+	switch r.Freq {
+	case "DAILY":
+		base = every(r.Interval, en, "day", "days", "каждый день", "каждый %d день", "каждые %d дня", "каждые %d дней")
+	case "WEEKLY":
+		base = every(r.Interval, en, "week", "weeks", "каждую неделю", "каждую %d неделю", "каждые %d недели", "каждые %d недель")
+	case "MONTHLY":
+		base = every(r.Interval, en, "month", "months", "каждый месяц",
+			"каждый %d месяц", "каждые %d месяца", "каждые %d месяцев")
+	default:
+		base = every(r.Interval, en, "year", "years", "каждый год", "каждый %d год", "каждые %d года", "каждые %d лет")
+	}
+
+	var parts, tail []string
+
+	if len(r.ByDay) > 0 {
+		var plain, items []string
+
+		for _, e := range r.ByDay {
+			if e.N == 0 {
+				plain = append(plain, weekdayName(e.Day, en))
+			} else {
+				items = append(items, weekNumName(e.N, e.Day, en))
+			}
+		}
+
+		if len(plain) > 0 {
+			items = append([]string{pick(en, "", "по ") + joinList(plain, en)}, items...)
+		}
+
+		parts = append(parts, pick(en, "on ", "")+joinList(items, en))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, pick(en, "on the "+monthDaysEN(r.ByMonthDay), monthDaysRU(r.ByMonthDay)))
+	}
+
+	if len(r.ByMonth) > 0 {
+		items := make([]string, 0, len(r.ByMonth))
+
+		for _, e := range r.ByMonth {
+			items = append(items, pick(en, time.Month(e).String(), ruMonthsPrep[e-1]))
+		}
+
+		parts = append(parts, pick(en, "in ", "в ")+joinList(items, en))
+	}
+
+	if len(r.BySetPos) > 0 {
+		items := make([]string, 0, len(r.BySetPos))
+
+		for _, e := range r.BySetPos {
+			items = append(items, fmt.Sprint(e))
+		}
+
+		tail = append(tail, pick(en, "position in period: ", "позиция в периоде: ")+strings.Join(items, ", "))
+	}
+
+	if r.Count > 0 {
+		tail = append(tail, pick(en, countEN(r.Count), pluralRU(r.Count, "%d раз", "%d раза", "%d раз")))
+	}
+
+	if !r.Until.IsZero() {
+		tail = append(tail, pick(en, "until "+r.Until.Format(time.DateOnly), "до "+r.Until.Format("02.01.2006")))
+	}
+
+	if len(parts) > 0 {
+		base += " " + strings.Join(parts, ", ")
+	}
+
+	return strings.Join(append([]string{base}, tail...), ", ")
+}
+
+// every описывает повторение каждые n единиц времени.
+func every(n int, en bool, unitEN, unitsEN, oneRU, oneNumRU, fewRU, manyRU string) string {
+	switch {
+	case en && n == 1:
+		return "every " + unitEN
+	case en:
+		return fmt.Sprintf("every %d %s", n, unitsEN)
+	case n == 1:
+		return oneRU
+	default:
+		return pluralRU(n, oneNumRU, fewRU, manyRU)
+	}
+}
+
+// pluralRU выбирает форму слова для числа по правилам русского языка.
+func pluralRU(n int, one, few, many string) string {
+	format := many
+
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		format = one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		format = few
+	}
+
+	return fmt.Sprintf(format, n)
+}
+
+// countEN описывает количество повторений на английском языке.
+func countEN(n int) string {
+	if n == 1 {
+		return "once"
+	}
+
+	return fmt.Sprintf("%d times", n)
+}
+
+// ordinalEN возвращает число с английским суффиксом порядкового числительного.
+func ordinalEN(n int) string {
+	suffix := "th"
+
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// weekdayName возвращает название дня недели: по-английски или по-русски в форме «по понедельникам».
+func weekdayName(day time.Weekday, en bool) string {
+	if en {
+		return day.String()
+	}
+
+	return ruWeekdays[day].datPl
+}
+
+// weekNumName описывает n-й день недели, например «во второй вторник» или «the last Friday».
+func weekNumName(num int, day time.Weekday, en bool) string {
+	if en {
+		switch {
+		case num == -1:
+			return "the last " + day.String()
+		case num < 0:
+			return "the " + ordinalEN(-num) + "-to-last " + day.String()
+		case num <= len(enOrdinals):
+			return "the " + enOrdinals[num-1] + " " + day.String()
+		default:
+			return "the " + ordinalEN(num) + " " + day.String()
+		}
+	}
+
+	wd := ruWeekdays[day]
+	endings := map[int]string{masculine: "й", feminine: "ю", neuter: "е"}
+
+	var ordinal string
+
+	switch {
+	case num == -1:
+		ordinal = ruOrdinals[wd.gender][len(ruOrdinals[wd.gender])-1]
+	case num < 0:
+		ordinal = fmt.Sprintf("%d-%s с конца", -num, endings[wd.gender])
+	case num < len(ruOrdinals[wd.gender]):
+		ordinal = ruOrdinals[wd.gender][num-1]
+	default:
+		ordinal = fmt.Sprintf("%d-%s", num, endings[wd.gender])
+	}
+
+	if strings.HasPrefix(ordinal, "вт") {
+		return "во " + ordinal + " " + wd.acc
+	}
+
+	return "в " + ordinal + " " + wd.acc
+}
+
+// monthDaysRU описывает дни месяца, например «1 и 15 числа» или «последний день».
+func monthDaysRU(days []int) string {
+	var nums, items []string
+
+	for _, e := range days {
+		switch {
+		case e > 0:
+			nums = append(nums, fmt.Sprint(e))
+		case e == -1:
+			items = append(items, "последний день")
+		case e == -2:
+			items = append(items, "предпоследний день")
+		default:
+			items = append(items, fmt.Sprintf("%d-й день с конца", -e))
+		}
+	}
+
+	if len(nums) > 0 {
+		items = append([]string{joinList(nums, false) + " числа"}, items...)
+	}
+
+	return joinList(items, false)
+}
+
+// monthDaysEN описывает дни месяца, например «1st and 15th» или «last day».
+func monthDaysEN(days []int) string {
+	var nums, items []string
+
+	for _, e := range days {
+		switch {
+		case e > 0:
+			nums = append(nums, ordinalEN(e))
+		case e == -1:
+			items = append(items, "last day")
+		case e == -2:
+			items = append(items, "second-to-last day")
+		default:
+			items = append(items, ordinalEN(-e)+"-to-last day")
+		}
+	}
+
+	return joinList(append(nums, items...), true)
+}
+
+// monthsList описывает список месяцев в родительном падеже или «каждого месяца».
+func monthsList(months []int, en bool) string {
+	if len(months) == 0 {
+		return pick(en, "every month", "каждого месяца")
+	}
+
+	items := make([]string, 0, len(months))
+
+	for _, e := range months {
+		items = append(items, pick(en, time.Month(e).String(), ruMonthsGen[e-1]))
+	}
+
+	return joinList(items, en)
+}
+
+// joinList соединяет элементы через запятую, а последний — через «и» или «and».
+func joinList(items []string, en bool) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+
+	return strings.Join(items[:len(items)-1], ", ") + pick(en, " and ", " и ") + items[len(items)-1]
+}
+
+// pick выбирает английский или русский вариант текста.
+func pick(en bool, textEN string, textRU string) string {
+	if en {
+		return textEN
+	}
+
+	return textRU
+}
@@ -0,0 +1,326 @@
+package date
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Коды ошибок правила повторения, которые возвращает Diagnose.
+const (
+	// CodeEmpty правило не указано.
+	CodeEmpty = "empty"
+	// CodeUnknownKind неизвестный тип правила.
+	CodeUnknownKind = "unknown_kind"
+	// CodeArguments неверное количество частей правила.
+	CodeArguments = "wrong_arguments"
+	// CodeDate неверная дата (errDate).
+	CodeDate = "invalid_date"
+	// CodeDays неверное число или номер дня (errDays).
+	CodeDays = "invalid_days"
+	// CodeRule неверное значение части правила (errRule).
+	CodeRule = "invalid_rule"
+)
+
+// Diagnostic описание ошибки в правиле повторения: что не так, где и как исправить.
+type Diagnostic struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Token неверная часть правила; пустая, если части не хватает.
+	Token string `json:"token"`
+	// Position смещение неверной части от начала правила в символах, начиная с 0.
+	Position   int    `json:"position"`
+	Suggestion string `json:"suggestion"`
+}
+
+// ruleToken часть правила и ее смещение в строке в байтах.
+type ruleToken struct {
+	value  string
+	offset int
+}
+
+// ruleArg аргумент правила: подсказка и пробное правило, в котором аргумент проверяется отдельно от остальных.
+type ruleArg struct {
+	hint string
+	// list аргумент — список через запятую, элементы проверяются по отдельности.
+	list  bool
+	probe func(value string) string
+}
+
+// ruleSyntax запись правила и его аргументы.
+type ruleSyntax struct {
+	usage   string
+	minArgs int
+	args    []ruleArg
+}
+
+var ruleSyntaxes = map[string]ruleSyntax{
+	KindDay: {usage: "d <число дней>", minArgs: 1, args: []ruleArg{
+		{hint: "укажите число дней от 1 до 400", probe: prefix("d ")},
+	}},
+	KindBusinessDay: {usage: "bd <число рабочих дней>", minArgs: 1, args: []ruleArg{
+		{hint: "укажите число рабочих дней от 1 до 400", probe: prefix("bd ")},
+	}},
+	KindHour: {usage: "h <число часов>", minArgs: 1, args: []ruleArg{
+		{hint: "укажите число часов от 1 до 24", probe: prefix("h ")},
+	}},
+	KindMinute: {usage: "min <число минут>", minArgs: 1, args: []ruleArg{
+		{hint: "укажите число минут от 1 до 1440", probe: prefix("min ")},
+	}},
+	KindWeek: {usage: "w <дни недели через запятую>", minArgs: 1, args: []ruleArg{
+		{hint: "укажите день недели от 1 (понедельник) до 7 (воскресенье)", list: true, probe: prefix("w ")},
+	}},
+	KindMonth: {usage: "m <дни через запятую> [<месяцы через запятую>]", minArgs: 1, args: []ruleArg{
+		{hint: "укажите день месяца от 1 до 31, -1 (последний) или -2 (предпоследний)", list: true, probe: prefix("m ")},
+		{hint: "укажите месяц от 1 до 12", list: true, probe: prefix("m 1 ")},
+	}},
+	KindWeekMonth: {usage: "wm <номер>:<день недели>[,...] [<месяцы через запятую>]", minArgs: 1, args: []ruleArg{
+		{
+			hint: "укажите номер недели от 1 до 5 или -1 и день недели от 1 до 7 через двоеточие, например 2:2",
+			list: true, probe: prefix("wm "),
+		},
+		{hint: "укажите месяц от 1 до 12", list: true, probe: prefix("wm 1:1 ")},
+	}},
+	KindYear: {usage: "y [<даты ММ-ДД через запятую>] [mar1|feb28|skip]", minArgs: 0, args: []ruleArg{
+		{hint: "укажите дату в формате ММ-ДД, например 03-08, или mar1, feb28, skip", list: true, probe: prefix("y ")},
+		{hint: "укажите mar1, feb28 или skip", probe: prefix("y 01-01 ")},
+	}},
+	KindQuota: {usage: "q <число выполнений> d|w|m|y", minArgs: 2, args: []ruleArg{
+		{hint: "укажите число выполнений от 1 до 100", probe: func(value string) string { return "q " + value + " d" }},
+		{hint: "укажите период: d, w, m или y", probe: prefix("q 1 ")},
+	}},
+	KindCron: {usage: "cron <минуты> <часы> <день месяца> <месяц> <день недели>", minArgs: cronFields},
+}
+
+// rruleHints подсказки для частей правила RRULE.
+var rruleHints = map[string]string{
+	"FREQ":       "укажите FREQ=DAILY, WEEKLY, MONTHLY или YEARLY",
+	"INTERVAL":   "укажите INTERVAL от 1 до 400",
+	"COUNT":      "укажите COUNT от 1",
+	"UNTIL":      "укажите UNTIL в формате 20060102",
+	"WKST":       "укажите WKST: MO, TU, WE, TH, FR, SA или SU",
+	"BYDAY":      "укажите дни недели через запятую, например BYDAY=MO,-1FR",
+	"BYMONTHDAY": "укажите дни месяца от 1 до 31 или от -31 до -1",
+	"BYMONTH":    "укажите месяцы от 1 до 12",
+	"BYSETPOS":   "укажите номера от 1 до 366 или от -366 до -1",
+}
+
+// Diagnose проверяет правило повторения и описывает первую найденную ошибку. Для верного правила возвращает nil.
+func Diagnose(repeat string) *Diagnostic {
+	_, err := Parse(repeat)
+	if err == nil {
+		return nil
+	}
+
+	if repeat == "" {
+		return newDiagnostic(repeat, CodeEmpty, errRule, ruleToken{}, "укажите правило, например «d 7»")
+	}
+
+	tokens := splitTokens(repeat, " ", 0)
+
+	var shift *ruleToken
+
+	if len(tokens) > 1 && tokens[len(tokens)-1].value == shiftModifier {
+		shift = &tokens[len(tokens)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	if len(tokens) == 1 && IsRRule(tokens[0].value) {
+		return diagnoseRRule(repeat, tokens[0], err)
+	}
+
+	syntax, ok := ruleSyntaxes[tokens[0].value]
+	if !ok {
+		return newDiagnostic(repeat, CodeUnknownKind, errRule, tokens[0], kindSuggestion(tokens[0].value))
+	}
+
+	if diagnostic := diagnoseArgs(repeat, tokens, syntax, err); diagnostic != nil {
+		return diagnostic
+	}
+
+	if shift != nil {
+		rule, _ := Parse(strings.TrimSuffix(repeat, " "+shiftModifier))
+		if rule.Timed() || rule.Kind == KindQuota {
+			return newDiagnostic(repeat, CodeRule, errRule, *shift,
+				"уберите shift: перенос на рабочий день не поддерживается для правил h, min, cron и q")
+		}
+	}
+
+	if errors.Is(err, errNoDates) {
+		return newDiagnostic(repeat, CodeDays, err, ruleToken{value: repeat},
+			"выберите дни, которые есть в указанных месяцах")
+	}
+
+	return newDiagnostic(repeat, codeOf(err), err, ruleToken{value: repeat}, "используйте формат: "+syntax.usage)
+}
+
+// diagnoseArgs проверяет количество аргументов правила и каждый аргумент отдельно.
+func diagnoseArgs(repeat string, tokens []ruleToken, syntax ruleSyntax, err error) *Diagnostic {
+	kind, args := tokens[0], tokens[1:]
+	usage := "используйте формат: " + syntax.usage
+
+	// Поля cron, как и в parseCron, разделяются любыми пробельными символами.
+	if kind.value == KindCron {
+		args = args[:0:0]
+
+		for _, e := range tokens[1:] {
+			args = append(args, fieldTokens(e)...)
+		}
+	}
+
+	maxArgs := max(len(syntax.args), syntax.minArgs)
+
+	if len(args) > maxArgs {
+		return newDiagnostic(repeat, CodeArguments, errRule, args[maxArgs], usage)
+	}
+
+	if len(args) < syntax.minArgs {
+		last := tokens[len(tokens)-1]
+
+		return newDiagnostic(repeat, CodeArguments, errRule,
+			ruleToken{offset: last.offset + len(last.value)}, usage)
+	}
+
+	var cronErr *CronFieldError
+	if errors.As(err, &cronErr) {
+		field := cronFieldsList[cronErr.Position-1]
+
+		return newDiagnostic(repeat, CodeRule, err, args[cronErr.Position-1],
+			"укажите поле «"+field.name+"» числом, диапазоном, списком или шагом, например */15")
+	}
+
+	for i, arg := range args {
+		if i >= len(syntax.args) {
+			break
+		}
+
+		items := []ruleToken{arg}
+		if syntax.args[i].list {
+			items = splitTokens(arg.value, ",", arg.offset)
+		}
+
+		for _, item := range items {
+			if _, err := Parse(syntax.args[i].probe(item.value)); err != nil {
+				return newDiagnostic(repeat, codeOf(err), err, item, syntax.args[i].hint)
+			}
+		}
+	}
+
+	return nil
+}
+
+// diagnoseRRule ищет неверную часть правила RRULE, проверяя каждую часть отдельно.
+func diagnoseRRule(repeat string, token ruleToken, err error) *Diagnostic {
+	value, offset := token.value, token.offset
+
+	if len(value) >= len(rrulePrefix) && strings.EqualFold(value[:len(rrulePrefix)], rrulePrefix) {
+		value, offset = value[len(rrulePrefix):], offset+len(rrulePrefix)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, part := range splitTokens(value, ";", offset) {
+		key, _, _ := strings.Cut(part.value, "=")
+		key = strings.ToUpper(key)
+
+		hint, ok := rruleHints[key]
+		if !ok {
+			return newDiagnostic(repeat, CodeRule, errRule, part,
+				"используйте части FREQ, INTERVAL, COUNT, UNTIL, WKST, BYDAY, BYMONTHDAY, BYMONTH или BYSETPOS")
+		}
+
+		if seen[key] {
+			return newDiagnostic(repeat, CodeRule, errRule, part, "уберите повторную часть "+key)
+		}
+
+		seen[key] = true
+
+		probe := part.value
+		if key != "FREQ" {
+			probe = "FREQ=YEARLY;" + probe
+		}
+
+		if _, err := ParseRRule(probe); err != nil {
+			return newDiagnostic(repeat, codeOf(err), err, part, hint)
+		}
+	}
+
+	if errors.Is(err, errNoDates) {
+		return newDiagnostic(repeat, CodeDays, err, token, "выберите дни BYMONTHDAY, которые есть в месяцах BYMONTH")
+	}
+
+	return newDiagnostic(repeat, codeOf(err), err, token,
+		"укажите FREQ; COUNT и UNTIL вместе не используются, номер дня в BYDAY допустим только для MONTHLY и YEARLY")
+}
+
+// splitTokens делит строку по разделителю и запоминает смещение каждой части.
+func splitTokens(value string, sep string, offset int) []ruleToken {
+	parts := strings.Split(value, sep)
+	tokens := make([]ruleToken, 0, len(parts))
+
+	for _, e := range parts {
+		tokens = append(tokens, ruleToken{value: e, offset: offset})
+		offset += len(e) + len(sep)
+	}
+
+	return tokens
+}
+
+// fieldTokens делит часть правила по пробельным символам, как strings.Fields, и запоминает смещение каждого поля.
+func fieldTokens(token ruleToken) []ruleToken {
+	var tokens []ruleToken
+
+	start := -1
+
+	for i, r := range token.value + " " {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			tokens = append(tokens, ruleToken{value: token.value[start:i], offset: token.offset + start})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+
+	return tokens
+}
+
+// newDiagnostic создает описание ошибки; смещение части правила переводится из байтов в символы.
+func newDiagnostic(repeat string, code string, err error, token ruleToken, suggestion string) *Diagnostic {
+	return &Diagnostic{
+		Code:       code,
+		Message:    err.Error(),
+		Token:      token.value,
+		Position:   utf8.RuneCountInString(repeat[:token.offset]),
+		Suggestion: suggestion,
+	}
+}
+
+// codeOf возвращает код ошибки по ошибке пакета.
+func codeOf(err error) string {
+	switch {
+	case errors.Is(err, errDate):
+		return CodeDate
+	case errors.Is(err, errDays), errors.Is(err, errNoDates):
+		return CodeDays
+	default:
+		return CodeRule
+	}
+}
+
+// kindSuggestion подсказывает тип правила: тот же тип в нижнем регистре или список всех типов.
+func kindSuggestion(kind string) string {
+	if _, ok := ruleSyntaxes[strings.ToLower(kind)]; ok {
+		return "используйте «" + strings.ToLower(kind) + "»"
+	}
+
+	return "используйте один из типов: d, bd, w, m, wm, y, h, min, cron, q или правило RRULE (FREQ=...)"
+}
+
+// prefix возвращает функцию, добавляющую к значению начало правила.
+func prefix(start string) func(value string) string {
+	return func(value string) string {
+		return start + value
+	}
+}
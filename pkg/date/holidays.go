@@ -0,0 +1,169 @@
+package date
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var errHolidays = errors.New("неверный формат календаря праздников")
+
+// holidayYears на сколько лет вперед от текущего года разворачиваются ежегодные события календаря.
+const holidayYears = 10
+
+// holidays содержит праздничные дни в формате 20060102.
+// Заполняется один раз при запуске сервера функцией LoadHolidays.
+var holidays = map[string]bool{}
+
+// LoadHolidays загружает праздничные дни из файла.
+// Поддерживаются календари ICS (события VEVENT с DTSTART/DTEND) и простые списки дат,
+// по одной дате на строку в формате 20060102 или 2006-01-02; строки, начинающиеся с #, пропускаются.
+func LoadHolidays(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения календаря праздников: %w", err)
+	}
+
+	var days map[string]bool
+
+	if strings.Contains(string(data), "BEGIN:VCALENDAR") {
+		days, err = parseICS(string(data))
+	} else {
+		days, err = parseDateList(string(data))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	holidays = days
+
+	return nil
+}
+
+// parseDateList разбирает список дат.
+func parseDateList(data string) (map[string]bool, error) {
+	days := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		day, err := time.Parse(dateFormat, line)
+		if err != nil {
+			day, err = time.Parse(time.DateOnly, line)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errHolidays, line)
+		}
+
+		days[day.Format(dateFormat)] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения календаря праздников: %w", err)
+	}
+
+	return days, nil
+}
+
+// parseICS разбирает календарь ICS. DTEND, как и в RFC 5545, не входит в событие.
+// Ежегодные события (RRULE с FREQ=YEARLY) разворачиваются на holidayYears лет вперед,
+// правила с другой частотой не поддерживаются.
+func parseICS(data string) (map[string]bool, error) {
+	days := make(map[string]bool)
+
+	// Длинные строки ICS переносятся с пробелом или табуляцией в начале следующей строки.
+	data = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(data)
+
+	var (
+		start, end time.Time
+		rule       RRule
+	)
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		name, value, _ := strings.Cut(line, ":")
+		name, _, _ = strings.Cut(name, ";")
+
+		var err error
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			start, end, rule = time.Time{}, time.Time{}, RRule{}
+		case "DTSTART":
+			start, err = parseICSDate(value)
+		case "DTEND":
+			end, err = parseICSDate(value)
+		case "RRULE":
+			rule, err = ParseRRule(value)
+			if err == nil && rule.Freq != "YEARLY" {
+				err = errHolidays
+			}
+		case "END":
+			if strings.ToUpper(value) != "VEVENT" || start.IsZero() {
+				continue
+			}
+
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+
+			for _, e := range holidayDates(rule, start) {
+				for day := e; day.Before(e.Add(end.Sub(start))); day = day.AddDate(0, 0, 1) {
+					days[day.Format(dateFormat)] = true
+				}
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errHolidays, line)
+		}
+	}
+
+	return days, nil
+}
+
+// holidayDates возвращает даты начала события: саму дату DTSTART и, если задано правило,
+// его повторения до конца периода holidayYears.
+func holidayDates(rule RRule, start time.Time) []time.Time {
+	dates := []time.Time{start}
+
+	if rule.Freq == "" {
+		return dates
+	}
+
+	end := time.Date(max(start.Year(), time.Now().Year())+holidayYears, time.January, 1, 0, 0, 0, 0, start.Location())
+
+	for date := start; ; {
+		next, _, err := rule.next(start, date)
+		if err != nil || !next.Before(end) {
+			return dates
+		}
+
+		dates = append(dates, next)
+		date = next
+	}
+}
+
+// parseICSDate разбирает значение DATE или DATE-TIME, оставляя только дату.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < len(dateFormat) {
+		return time.Time{}, errHolidays
+	}
+
+	day, err := time.Parse(dateFormat, value[:len(dateFormat)])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w", errHolidays)
+	}
+
+	return day, nil
+}
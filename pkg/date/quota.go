@@ -0,0 +1,113 @@
+package date
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxQuota наибольшее количество выполнений за период в правиле q.
+const maxQuota = 100
+
+// Периоды правила q.
+const (
+	PeriodDay   = "d"
+	PeriodWeek  = "w"
+	PeriodMonth = "m"
+	PeriodYear  = "y"
+)
+
+// periodStart возвращает первый день периода, в который попадает дата.
+func periodStart(period string, date time.Time) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	switch period {
+	case PeriodWeek:
+		return date.AddDate(0, 0, -((int(date.Weekday()) + daysInWeek - 1) % daysInWeek))
+	case PeriodMonth:
+		return date.AddDate(0, 0, 1-date.Day())
+	case PeriodYear:
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+	default:
+		return date
+	}
+}
+
+// nextPeriod возвращает первый день периода, следующего за периодом даты.
+func nextPeriod(period string, date time.Time) time.Time {
+	start := periodStart(period, date)
+
+	switch period {
+	case PeriodWeek:
+		return start.AddDate(0, 0, daysInWeek)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case PeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// quotaRule вычисляет начало следующего периода правила q: туда задача переносится после выполнения нормы.
+func quotaRule(now time.Time, date time.Time, period string) (time.Time, error) {
+	if now.After(date) {
+		date = now
+	}
+
+	return nextPeriod(period, date), nil
+}
+
+// quotaCurrent разбирает правило q и возвращает дату, на которую засчитывается выполнение:
+// дату задачи или сегодняшнюю, если дата задачи в прошлом.
+func quotaCurrent(now time.Time, dateString string, repeat string) (Rule, time.Time, error) {
+	rule, err := Parse(repeat)
+	if err != nil {
+		return Rule{}, time.Time{}, err
+	}
+
+	if rule.Kind != KindQuota {
+		return Rule{}, time.Time{}, fmt.Errorf("%w", errRule)
+	}
+
+	current, err := time.ParseInLocation(dateFormat, dateString, now.Location())
+	if err != nil {
+		return Rule{}, time.Time{}, fmt.Errorf("%w", errDate)
+	}
+
+	if today := periodStart(PeriodDay, now); today.After(current) {
+		current = today
+	}
+
+	return rule, current, nil
+}
+
+// QuotaPeriod возвращает дату, на которую засчитывается выполнение задачи с правилом q, и границы
+// ее периода: первый день периода и первый день следующего периода. Выполнения с датами внутри
+// этих границ составляют прогресс нормы; если период даты задачи закончился, а норма так и не
+// выполнена, счет начинается заново.
+func QuotaPeriod(now time.Time, dateString string, repeat string) (string, string, string, error) {
+	rule, current, err := quotaCurrent(now, dateString, repeat)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return current.Format(dateFormat), periodStart(rule.Period, current).Format(dateFormat),
+		nextPeriod(rule.Period, current).Format(dateFormat), nil
+}
+
+// QuotaDone засчитывает выполнение задачи с правилом q («q 3 w» — три раза в неделю).
+// done — число выполнений в периоде (см. QuotaPeriod) без учета засчитываемого. Пока норма
+// не выполнена, задача остается на текущей дате (или переходит на сегодня, если дата в прошлом),
+// а после выполнения нормы переносится на начало следующего периода; тогда второе значение — true.
+func QuotaDone(now time.Time, dateString string, repeat string, done int) (string, bool, error) {
+	rule, current, err := quotaCurrent(now, dateString, repeat)
+	if err != nil {
+		return "", false, err
+	}
+
+	if done+1 >= rule.Interval {
+		return nextPeriod(rule.Period, current).Format(dateFormat), true, nil
+	}
+
+	return current.Format(dateFormat), false, nil
+}
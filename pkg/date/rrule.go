@@ -0,0 +1,531 @@
+package date
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSeriesEnd возвращается, когда повторения закончились по COUNT или UNTIL.
+var ErrSeriesEnd = errors.New("повторения задачи закончились")
+
+const (
+	rrulePrefix  = "RRULE:"
+	maxWeekNumY  = 53
+	maxSetPos    = 366
+	daysInWeek   = 7
+	maxInterval  = 400
+	untilDateLen = 8
+)
+
+var weekDays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum день недели из BYDAY с необязательным порядковым номером (0 — любой, -1 — последний).
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// RRule правило повторения в формате RFC 5545 (поддерживаются только даты, без времени).
+type RRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	Count      int
+	Until      time.Time
+	WeekStart  time.Weekday
+}
+
+// IsRRule проверяет, записано ли правило в формате RRULE.
+func IsRRule(repeat string) bool {
+	upper := strings.ToUpper(repeat)
+
+	return strings.HasPrefix(upper, rrulePrefix) || strings.HasPrefix(upper, "FREQ=")
+}
+
+// ParseRRule разбирает правило в формате RRULE, например FREQ=MONTHLY;BYDAY=-1FR.
+func ParseRRule(repeat string) (RRule, error) {
+	rule := RRule{Interval: 1, WeekStart: time.Monday}
+
+	if len(repeat) >= len(rrulePrefix) && strings.EqualFold(repeat[:len(rrulePrefix)], rrulePrefix) {
+		repeat = repeat[len(rrulePrefix):]
+	}
+
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(repeat, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+
+		if !ok || value == "" || seen[key] {
+			return RRule{}, fmt.Errorf("%w", errRule)
+		}
+
+		seen[key] = true
+
+		if err := rule.set(key, strings.ToUpper(value)); err != nil {
+			return RRule{}, err
+		}
+	}
+
+	if rule.Freq == "" || (rule.Count > 0 && !rule.Until.IsZero()) {
+		return RRule{}, fmt.Errorf("%w", errRule)
+	}
+
+	for _, e := range rule.ByDay {
+		if e.N != 0 && rule.Freq != "MONTHLY" && rule.Freq != "YEARLY" {
+			return RRule{}, fmt.Errorf("%w", errRule)
+		}
+
+		if e.N != 0 && rule.Freq == "MONTHLY" && (e.N > maxWeekNum || e.N < -maxWeekNum) {
+			return RRule{}, fmt.Errorf("%w", errDays)
+		}
+	}
+
+	if len(rule.ByMonthDay) > 0 {
+		if err := validateMonthDays(rule.ByMonthDay, rule.ByMonth); err != nil {
+			return RRule{}, err
+		}
+	}
+
+	return rule, nil
+}
+
+// set заполняет одну часть правила.
+func (r *RRule) set(key string, value string) error {
+	var err error
+
+	switch key {
+	case "FREQ":
+		if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" && value != "YEARLY" {
+			return fmt.Errorf("%w", errRule)
+		}
+
+		r.Freq = value
+	case "INTERVAL":
+		r.Interval, err = strconv.Atoi(value)
+		if err != nil || r.Interval < 1 || r.Interval > maxInterval {
+			return fmt.Errorf("%w", errDays)
+		}
+	case "COUNT":
+		r.Count, err = strconv.Atoi(value)
+		if err != nil || r.Count < 1 {
+			return fmt.Errorf("%w", errRule)
+		}
+	case "UNTIL":
+		if len(value) < untilDateLen {
+			return fmt.Errorf("%w", errDate)
+		}
+
+		r.Until, err = time.Parse(dateFormat, value[:untilDateLen])
+		if err != nil {
+			return fmt.Errorf("%w", errDate)
+		}
+	case "WKST":
+		day, ok := weekDays[value]
+		if !ok {
+			return fmt.Errorf("%w", errDays)
+		}
+
+		r.WeekStart = day
+	case "BYDAY":
+		r.ByDay, err = parseByDay(value)
+	case "BYMONTHDAY":
+		r.ByMonthDay, err = parseNumList(value, -maxMDay, maxMDay)
+	case "BYMONTH":
+		r.ByMonth, err = parseNumList(value, minMonth, maxMonth)
+	case "BYSETPOS":
+		r.BySetPos, err = parseNumList(value, -maxSetPos, maxSetPos)
+	default:
+		return fmt.Errorf("%w", errRule)
+	}
+
+	return err
+}
+
+// parseByDay разбирает список дней недели вида MO,2TU,-1FR.
+func parseByDay(value string) ([]WeekdayNum, error) {
+	parts := strings.Split(value, ",")
+	days := make([]WeekdayNum, 0, len(parts))
+
+	for _, e := range parts {
+		if len(e) < 2 {
+			return nil, fmt.Errorf("%w", errDays)
+		}
+
+		day, ok := weekDays[e[len(e)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w", errDays)
+		}
+
+		var num int
+
+		if prefix := e[:len(e)-2]; prefix != "" {
+			var err error
+
+			num, err = strconv.Atoi(prefix)
+			if err != nil || num == 0 || num > maxWeekNumY || num < -maxWeekNumY {
+				return nil, fmt.Errorf("%w", errDays)
+			}
+		}
+
+		days = append(days, WeekdayNum{N: num, Day: day})
+	}
+
+	return days, nil
+}
+
+// parseNumList разбирает список ненулевых чисел в указанных пределах.
+func parseNumList(value string, minValue int, maxValue int) ([]int, error) {
+	parts := strings.Split(value, ",")
+	nums := make([]int, 0, len(parts))
+
+	for _, e := range parts {
+		num, err := strconv.Atoi(e)
+		if err != nil || num == 0 || num < minValue || num > maxValue {
+			return nil, fmt.Errorf("%w", errDays)
+		}
+
+		nums = append(nums, num)
+	}
+
+	return nums, nil
+}
+
+// rruleRule вычисляет следующую дату по правилу RRULE.
+func rruleRule(now time.Time, date time.Time, repeat string) (time.Time, error) {
+	rule, err := ParseRRule(repeat)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next, _, err := rule.next(date, now)
+
+	return next, err
+}
+
+// NextRepeat вычисляет следующую дату, время и правило, которое нужно сохранить вместе с ними.
+// Для RRULE с COUNT счетчик уменьшается на число пройденных повторений, чтобы правило
+// с новой датой начала описывало оставшуюся часть серии. Остальные правила не меняются.
+// Пропущенные даты из except, как и EXDATE в RFC 5545, тоже уменьшают COUNT.
+func NextRepeat(now time.Time, dateString string, timeString string, repeat string,
+	except ...string,
+) (string, string, string, error) {
+	next, nextTime, err := NextDateTime(now, dateString, timeString, repeat, except...)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	parsed, err := Parse(repeat)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// Правило y без дат, заданное для 29 февраля, запоминает эту дату: иначе после
+	// переноса на 28 февраля или 1 марта задача больше не вернулась бы на 29 февраля.
+	if parsed.Kind == KindYear && len(parsed.YearDates) == 0 && parsed.Feb29 != "" &&
+		strings.HasSuffix(dateString, "0229") {
+		parsed.YearDates = []YearDate{{Month: int(time.February), Day: 29}}
+
+		return next, nextTime, parsed.String(), nil
+	}
+
+	if parsed.Kind != KindRRule {
+		return next, nextTime, repeat, nil
+	}
+
+	rule, err := ParseRRule(parsed.RRule)
+	if err != nil || rule.Count == 0 {
+		return next, nextTime, repeat, err
+	}
+
+	date, err := time.ParseInLocation(dateFormat, dateString, now.Location())
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w", errDate)
+	}
+
+	nextDay, err := time.ParseInLocation(dateFormat, next, now.Location())
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w", errDate)
+	}
+
+	_, passed, err := rule.next(date, nextDay.AddDate(0, 0, -1))
+	if err != nil {
+		return "", "", "", err
+	}
+
+	rule.Count -= passed
+	parsed.RRule = rule.String()
+
+	return next, nextTime, parsed.String(), nil
+}
+
+// String возвращает каноническую запись правила: части в фиксированном порядке,
+// значения по умолчанию (INTERVAL=1, WKST=MO) опускаются.
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		items := make([]string, 0, len(r.ByDay))
+
+		for _, e := range r.ByDay {
+			item := weekDayCode(e.Day)
+			if e.N != 0 {
+				item = strconv.Itoa(e.N) + item
+			}
+
+			items = append(items, item)
+		}
+
+		parts = append(parts, "BYDAY="+strings.Join(items, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format(dateFormat))
+	}
+
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekDayCode(r.WeekStart))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// weekDayCode возвращает двухбуквенный код дня недели.
+func weekDayCode(day time.Weekday) string {
+	for code, e := range weekDays {
+		if e == day {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// next ищет первое повторение после даты задачи и текущей даты.
+// Дата задачи считается началом серии (DTSTART). Вторым значением возвращается
+// число повторений серии, пришедшихся на период до найденной даты.
+func (r RRule) next(dtstart time.Time, now time.Time) (time.Time, int, error) {
+	after := dtstart
+
+	if now.After(after) {
+		after = now
+	}
+
+	period := 0
+
+	// Без COUNT можно не перебирать серию с самого начала.
+	if r.Count == 0 {
+		period = max(r.periodsBetween(dtstart, after)/r.Interval*r.Interval-r.Interval, 0)
+	}
+
+	passed := 0
+	until := time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day(), 0, 0, 0, 0, dtstart.Location())
+
+	// Поиск прекращается, если за searchYears лет подряд не нашлось ни одной даты.
+	found := r.periodStart(dtstart, period)
+
+	for ; ; period += r.Interval {
+		start := r.periodStart(dtstart, period)
+		dates := r.expand(dtstart, start)
+
+		if len(dates) == 0 {
+			if start.After(found.AddDate(searchYears, 0, 0)) {
+				break
+			}
+
+			continue
+		}
+
+		found = start
+
+		for _, date := range dates {
+			if date.Before(dtstart) {
+				continue
+			}
+
+			if (!r.Until.IsZero() && date.After(until)) || (r.Count > 0 && passed >= r.Count) {
+				return time.Time{}, passed, fmt.Errorf("%w", ErrSeriesEnd)
+			}
+
+			if date.After(after) {
+				return date, passed, nil
+			}
+
+			passed++
+		}
+	}
+
+	return time.Time{}, passed, fmt.Errorf("%w", errRule)
+}
+
+// periodsBetween возвращает число периодов правила между двумя датами.
+func (r RRule) periodsBetween(from time.Time, to time.Time) int {
+	switch r.Freq {
+	case "DAILY":
+		return daysBetween(from, to)
+	case "WEEKLY":
+		return daysBetween(r.weekStart(from), r.weekStart(to)) / daysInWeek
+	case "MONTHLY":
+		return (to.Year()-from.Year())*maxMonth + int(to.Month()) - int(from.Month())
+	default:
+		return to.Year() - from.Year()
+	}
+}
+
+// periodStart возвращает первый день периода с указанным номером, считая от начала серии.
+func (r RRule) periodStart(dtstart time.Time, period int) time.Time {
+	switch r.Freq {
+	case "DAILY":
+		return dtstart.AddDate(0, 0, period)
+	case "WEEKLY":
+		return r.weekStart(dtstart).AddDate(0, 0, daysInWeek*period)
+	case "MONTHLY":
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(period), 1, 0, 0, 0, 0, dtstart.Location())
+	default:
+		return time.Date(dtstart.Year()+period, 1, 1, 0, 0, 0, 0, dtstart.Location())
+	}
+}
+
+// weekStart возвращает начало недели с учетом WKST.
+func (r RRule) weekStart(date time.Time) time.Time {
+	shift := (int(date.Weekday()) - int(r.WeekStart) + daysInWeek) % daysInWeek
+
+	return date.AddDate(0, 0, -shift)
+}
+
+// expand возвращает упорядоченные даты повторений внутри одного периода.
+func (r RRule) expand(dtstart time.Time, start time.Time) []time.Time {
+	var end time.Time
+
+	switch r.Freq {
+	case "DAILY":
+		end = start.AddDate(0, 0, 1)
+	case "WEEKLY":
+		end = start.AddDate(0, 0, daysInWeek)
+	case "MONTHLY":
+		end = start.AddDate(0, 1, 0)
+	default:
+		end = start.AddDate(1, 0, 0)
+	}
+
+	var dates []time.Time
+
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		if r.match(dtstart, date) {
+			dates = append(dates, date)
+		}
+	}
+
+	if len(r.BySetPos) == 0 || len(dates) == 0 {
+		return dates
+	}
+
+	selected := make([]time.Time, 0, len(r.BySetPos))
+
+	for _, pos := range r.BySetPos {
+		if pos < 0 {
+			pos += len(dates) + 1
+		}
+
+		if pos >= 1 && pos <= len(dates) && !slices.Contains(selected, dates[pos-1]) {
+			selected = append(selected, dates[pos-1])
+		}
+	}
+
+	slices.SortFunc(selected, func(a, b time.Time) int { return a.Compare(b) })
+
+	return selected
+}
+
+// match проверяет, подходит ли день под правило внутри своего периода.
+func (r RRule) match(dtstart time.Time, date time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(date.Month())) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 &&
+		!slices.Contains(r.ByMonthDay, date.Day()) && !slices.Contains(r.ByMonthDay, date.Day()-lastDay(date)-1) {
+		return false
+	}
+
+	if len(r.ByDay) > 0 {
+		return r.matchByDay(date)
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		return true
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		return true
+	case "WEEKLY":
+		return date.Weekday() == dtstart.Weekday()
+	case "MONTHLY":
+		return date.Day() == dtstart.Day()
+	default:
+		return date.Day() == dtstart.Day() && (len(r.ByMonth) > 0 || date.Month() == dtstart.Month())
+	}
+}
+
+// matchByDay проверяет день по BYDAY. Порядковый номер считается внутри месяца,
+// а для YEARLY без BYMONTH — внутри года.
+func (r RRule) matchByDay(date time.Time) bool {
+	for _, e := range r.ByDay {
+		if date.Weekday() != e.Day {
+			continue
+		}
+
+		if e.N == 0 {
+			return true
+		}
+
+		day, total := date.Day(), lastDay(date)
+
+		if r.Freq == "YEARLY" && len(r.ByMonth) == 0 {
+			day = date.YearDay()
+			total = time.Date(date.Year(), 12, 31, 0, 0, 0, 0, date.Location()).YearDay()
+		}
+
+		if (e.N > 0 && (day-1)/daysInWeek+1 == e.N) || (e.N < 0 && (total-day)/daysInWeek+1 == -e.N) {
+			return true
+		}
+	}
+
+	return false
+}
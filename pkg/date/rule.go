@@ -0,0 +1,488 @@
+package date
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Виды правил повторения.
+const (
+	KindDay         = "d"
+	KindBusinessDay = "bd"
+	KindYear        = "y"
+	KindWeek        = "w"
+	KindMonth       = "m"
+	KindWeekMonth   = "wm"
+	KindRRule       = "rrule"
+	KindHour        = "h"
+	KindMinute      = "min"
+	KindCron        = "cron"
+	KindQuota       = "q"
+)
+
+// WeekNum n-й день недели месяца: номер от 1 до 5 или -1 (последний), день недели от 1 (понедельник) до 7.
+type WeekNum struct {
+	Num     int `json:"num"`
+	Weekday int `json:"weekday"`
+}
+
+// Rule разобранное правило повторения. Правило получают из строки функцией Parse
+// или из JSON; во втором случае его нужно проверить методом Validate.
+type Rule struct {
+	// Kind вид правила: d, bd, y, w, m, wm, h, min, cron, q или rrule.
+	Kind string `json:"kind"`
+	// Interval количество дней для правил d и bd, часов для h, минут для min, выполнений за период для q.
+	Interval int `json:"interval,omitempty"`
+	// Period период правила q: d, w, m или y.
+	Period string `json:"period,omitempty"`
+	// YearDates даты года для правила y; без них правило повторяет дату задачи.
+	YearDates []YearDate `json:"year_dates,omitempty"`
+	// Feb29 что делать с 29 февраля в невисокосный год: mar1 (по умолчанию), feb28 или skip.
+	Feb29 string `json:"feb29,omitempty"`
+	// Weekdays дни недели для правила w: 1 — понедельник, 7 — воскресенье.
+	Weekdays []int `json:"weekdays,omitempty"`
+	// MonthDays дни месяца для правила m: -1 — последний день, -2 — предпоследний.
+	MonthDays []int `json:"month_days,omitempty"`
+	// WeekNums дни недели месяца для правила wm.
+	WeekNums []WeekNum `json:"week_nums,omitempty"`
+	// Months необязательный список месяцев для правил m и wm.
+	Months []int `json:"months,omitempty"`
+	// RRule правило в формате RFC 5545 для вида rrule.
+	RRule string `json:"rrule,omitempty"`
+	// Cron пять полей выражения cron для вида cron: минуты, часы, день месяца, месяц, день недели.
+	Cron string `json:"cron,omitempty"`
+	// Shift переносит даты с выходных и праздников на ближайший рабочий день.
+	Shift bool `json:"shift,omitempty"`
+}
+
+// Parse разбирает и проверяет строку правила повторения, например «w 1,4» или «FREQ=WEEKLY;BYDAY=MO».
+func Parse(repeat string) (Rule, error) {
+	var rule Rule
+
+	repeatSlice := strings.Split(repeat, " ")
+
+	if len(repeatSlice) > 1 && repeatSlice[len(repeatSlice)-1] == shiftModifier {
+		rule.Shift = true
+		repeatSlice = repeatSlice[:len(repeatSlice)-1]
+	}
+
+	if len(repeatSlice) == 1 && IsRRule(repeatSlice[0]) {
+		rule.Kind = KindRRule
+		rule.RRule = repeatSlice[0]
+
+		return rule, rule.Validate()
+	}
+
+	rule.Kind = repeatSlice[0]
+
+	var err error
+
+	switch rule.Kind {
+	case KindDay, KindBusinessDay, KindHour, KindMinute:
+		if len(repeatSlice) != 2 {
+			return Rule{}, fmt.Errorf("%w", errRule)
+		}
+
+		rule.Interval, err = strconv.Atoi(repeatSlice[1])
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w", errDays)
+		}
+	case KindYear:
+		if len(repeatSlice) > 3 {
+			return Rule{}, fmt.Errorf("%w", errRule)
+		}
+
+		// Без списка дат способ обработки 29 февраля относится к дате самой задачи: «y feb28».
+		switch {
+		case len(repeatSlice) == 2 && isFeb29Policy(repeatSlice[1]):
+			rule.Feb29 = repeatSlice[1]
+		case len(repeatSlice) > 1:
+			rule.YearDates, err = parseYearDates(repeatSlice[1])
+		}
+
+		if len(repeatSlice) == 3 {
+			rule.Feb29 = repeatSlice[2]
+		}
+	case KindWeek:
+		if len(repeatSlice) != 2 {
+			return Rule{}, fmt.Errorf("%w", errRule)
+		}
+
+		rule.Weekdays, err = parseInts(repeatSlice[1], errDays)
+	case KindCron:
+		rule.Cron = strings.Join(repeatSlice[1:], " ")
+	case KindQuota:
+		if len(repeatSlice) != 3 {
+			return Rule{}, fmt.Errorf("%w", errRule)
+		}
+
+		rule.Interval, err = strconv.Atoi(repeatSlice[1])
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w", errRule)
+		}
+
+		rule.Period = repeatSlice[2]
+	case KindMonth, KindWeekMonth:
+		if len(repeatSlice) != 2 && len(repeatSlice) != 3 {
+			return Rule{}, fmt.Errorf("%w", errRule)
+		}
+
+		if rule.Kind == KindMonth {
+			rule.MonthDays, err = parseInts(repeatSlice[1], errDays)
+		} else {
+			rule.WeekNums, err = parseWeekNums(repeatSlice[1])
+		}
+
+		if err == nil && len(repeatSlice) == 3 {
+			rule.Months, err = parseInts(repeatSlice[2], errRule)
+		}
+	default:
+		return Rule{}, fmt.Errorf("%w", errRule)
+	}
+
+	if err != nil {
+		return Rule{}, err
+	}
+
+	return rule, rule.Validate()
+}
+
+// parseInts разбирает список чисел через запятую.
+func parseInts(value string, errValue error) ([]int, error) {
+	parts := strings.Split(value, ",")
+	nums := make([]int, 0, len(parts))
+
+	for _, e := range parts {
+		num, err := strconv.Atoi(e)
+		if err != nil {
+			return nil, fmt.Errorf("%w", errValue)
+		}
+
+		nums = append(nums, num)
+	}
+
+	return nums, nil
+}
+
+// parseWeekNums разбирает пары <номер>:<день недели> из правила wm.
+func parseWeekNums(value string) ([]WeekNum, error) {
+	pairs := strings.Split(value, ",")
+	nums := make([]WeekNum, 0, len(pairs))
+
+	for _, e := range pairs {
+		numString, wDayString, ok := strings.Cut(e, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w", errRule)
+		}
+
+		num, err := strconv.Atoi(numString)
+		if err != nil {
+			return nil, fmt.Errorf("%w", errDays)
+		}
+
+		wDay, err := strconv.Atoi(wDayString)
+		if err != nil {
+			return nil, fmt.Errorf("%w", errDays)
+		}
+
+		nums = append(nums, WeekNum{Num: num, Weekday: wDay})
+	}
+
+	return nums, nil
+}
+
+// Validate проверяет, что правило заполнено полностью и значения не выходят за допустимые пределы.
+func (r Rule) Validate() error {
+	if !r.onlyFields() {
+		return fmt.Errorf("%w", errRule)
+	}
+
+	// Перенос на рабочий день не сохраняет время повторений, поэтому для правил h, min и cron не поддерживается.
+	// Правило q переходит к началу следующего периода по числу выполнений, и перенос в нем не применяется.
+	if r.Shift && (r.Timed() || r.Kind == KindQuota) {
+		return fmt.Errorf("%w", errRule)
+	}
+
+	switch r.Kind {
+	case KindDay, KindBusinessDay:
+		if r.Interval < minDays || r.Interval > maxDays {
+			return fmt.Errorf("%w", errDays)
+		}
+	case KindHour:
+		if r.Interval < 1 || r.Interval > maxHours {
+			return fmt.Errorf("%w", errRule)
+		}
+	case KindMinute:
+		if r.Interval < 1 || r.Interval > maxMinutes {
+			return fmt.Errorf("%w", errRule)
+		}
+	case KindYear:
+		return validateYearDates(r.YearDates, r.Feb29)
+	case KindWeek:
+		if len(r.Weekdays) == 0 {
+			return fmt.Errorf("%w", errRule)
+		}
+
+		for _, e := range r.Weekdays {
+			if e < minWDay || e > maxWDay {
+				return fmt.Errorf("%w", errDays)
+			}
+		}
+	case KindMonth:
+		if len(r.MonthDays) == 0 {
+			return fmt.Errorf("%w", errRule)
+		}
+
+		for _, e := range r.MonthDays {
+			if e < minNegMDay || e > maxMDay || e == 0 {
+				return fmt.Errorf("%w", errDays)
+			}
+		}
+
+		if err := validateMonths(r.Months); err != nil {
+			return err
+		}
+
+		return validateMonthDays(r.MonthDays, r.Months)
+	case KindWeekMonth:
+		if len(r.WeekNums) == 0 {
+			return fmt.Errorf("%w", errRule)
+		}
+
+		for _, e := range r.WeekNums {
+			if e.Num < -1 || e.Num > maxWeekNum || e.Num == 0 || e.Weekday < minWDay || e.Weekday > maxWDay {
+				return fmt.Errorf("%w", errDays)
+			}
+		}
+
+		return validateMonths(r.Months)
+	case KindRRule:
+		if _, err := ParseRRule(r.RRule); err != nil {
+			return err
+		}
+	case KindCron:
+		if _, err := parseCron(r.Cron); err != nil {
+			return err
+		}
+	case KindQuota:
+		if r.Interval < 1 || r.Interval > maxQuota {
+			return fmt.Errorf("%w", errRule)
+		}
+
+		if r.Period != PeriodDay && r.Period != PeriodWeek && r.Period != PeriodMonth && r.Period != PeriodYear {
+			return fmt.Errorf("%w", errRule)
+		}
+	default:
+		return fmt.Errorf("%w", errRule)
+	}
+
+	return nil
+}
+
+// onlyFields проверяет, что заполнены только поля, которые относятся к виду правила.
+func (r Rule) onlyFields() bool {
+	return (r.Interval == 0 || r.Kind == KindDay || r.Kind == KindBusinessDay || r.Kind == KindHour ||
+		r.Kind == KindMinute || r.Kind == KindQuota) &&
+		(r.Period == "" || r.Kind == KindQuota) &&
+		(len(r.Weekdays) == 0 || r.Kind == KindWeek) &&
+		(len(r.MonthDays) == 0 || r.Kind == KindMonth) &&
+		(len(r.WeekNums) == 0 || r.Kind == KindWeekMonth) &&
+		(len(r.Months) == 0 || r.Kind == KindMonth || r.Kind == KindWeekMonth) &&
+		(r.RRule == "" || r.Kind == KindRRule) &&
+		(r.Cron == "" || r.Kind == KindCron) &&
+		(len(r.YearDates) == 0 && r.Feb29 == "" || r.Kind == KindYear)
+}
+
+// Timed проверяет, что правило задает не только дату, но и время повторений (правила h, min и cron).
+func (r Rule) Timed() bool {
+	return r.Kind == KindHour || r.Kind == KindMinute || r.Kind == KindCron
+}
+
+// validateMonths проверяет необязательный список месяцев.
+func validateMonths(months []int) error {
+	for _, e := range months {
+		if e < minMonth || e > maxMonth {
+			return fmt.Errorf("%w", errRule)
+		}
+	}
+
+	return nil
+}
+
+// validateMonthDays проверяет, что хотя бы один из дней есть хотя бы в одном из месяцев:
+// правило «m 30 2» записано верно, но никогда не срабатывает. Отрицательные дни считаются с конца месяца.
+func validateMonthDays(days []int, months []int) error {
+	if len(months) == 0 {
+		return nil
+	}
+
+	for _, day := range days {
+		for _, month := range months {
+			if max(day, -day) <= monthDays(month) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w", errNoDates)
+}
+
+// Next возвращает первую дату повторения после after, считая after датой текущего повторения.
+func (r Rule) Next(after time.Time) (time.Time, error) {
+	return r.NextFrom(after, after)
+}
+
+// NextFrom возвращает первую дату повторения после after для задачи с датой date.
+func (r Rule) NextFrom(date time.Time, after time.Time) (time.Time, error) {
+	if err := r.Validate(); err != nil {
+		return time.Time{}, err
+	}
+
+	return r.next(after, date)
+}
+
+// next выбирает способ вычисления даты по виду правила. Правило должно быть проверено.
+func (r Rule) next(now time.Time, date time.Time) (time.Time, error) {
+	if r.Shift {
+		base := r
+		base.Shift = false
+
+		return shiftRule(now, date, base)
+	}
+
+	switch r.Kind {
+	case KindDay:
+		return dayRule(now, date, r.Interval)
+	case KindBusinessDay:
+		return businessDayRule(now, date, r.Interval)
+	case KindHour:
+		return subDailyRule(now, date, time.Duration(r.Interval)*time.Hour)
+	case KindMinute:
+		return subDailyRule(now, date, time.Duration(r.Interval)*time.Minute)
+	case KindYear:
+		if len(r.YearDates) > 0 {
+			return yearDatesRule(now, date, r.YearDates, r.Feb29)
+		}
+
+		if r.Feb29 != "" {
+			return yearDatesRule(now, date, []YearDate{{Month: int(date.Month()), Day: date.Day()}}, r.Feb29)
+		}
+
+		return yearRule(now, date)
+	case KindWeek:
+		return weekRule(now, date, r.Weekdays)
+	case KindMonth:
+		return monthRule(now, date, r.MonthDays, r.Months)
+	case KindWeekMonth:
+		return weekMonthRule(now, date, r.WeekNums, r.Months)
+	case KindRRule:
+		return rruleRule(now, date, r.RRule)
+	case KindCron:
+		return cronRule(now, date, r.Cron)
+	case KindQuota:
+		return quotaRule(now, date, r.Period)
+	default:
+		return time.Time{}, fmt.Errorf("%w", errRule)
+	}
+}
+
+// String возвращает каноническую запись правила: списки упорядочены и не содержат повторов,
+// RRULE записан заглавными буквами в фиксированном порядке частей.
+func (r Rule) String() string {
+	var repeat string
+
+	switch r.Kind {
+	case KindDay, KindBusinessDay, KindHour, KindMinute:
+		repeat = r.Kind + " " + strconv.Itoa(r.Interval)
+	case KindWeek:
+		repeat = r.Kind + " " + joinInts(sortedInts(r.Weekdays))
+	case KindMonth:
+		repeat = r.Kind + " " + joinInts(sortedInts(r.MonthDays)) + monthsSuffix(r.Months)
+	case KindWeekMonth:
+		nums := slices.Clone(r.WeekNums)
+		slices.SortFunc(nums, func(a, b WeekNum) int {
+			if a.Num != b.Num {
+				return compareDays(a.Num, b.Num)
+			}
+
+			return cmp.Compare(a.Weekday, b.Weekday)
+		})
+
+		items := make([]string, 0, len(nums))
+
+		for _, e := range slices.Compact(nums) {
+			items = append(items, strconv.Itoa(e.Num)+":"+strconv.Itoa(e.Weekday))
+		}
+
+		repeat = r.Kind + " " + strings.Join(items, ",") + monthsSuffix(r.Months)
+	case KindCron:
+		repeat = r.Kind + " " + strings.Join(strings.Fields(r.Cron), " ")
+	case KindQuota:
+		repeat = r.Kind + " " + strconv.Itoa(r.Interval) + " " + r.Period
+	case KindYear:
+		repeat = r.Kind
+
+		if len(r.YearDates) > 0 {
+			repeat += " " + yearDatesString(r.YearDates)
+		}
+
+		if r.Feb29 != "" && r.Feb29 != Feb29Mar1 {
+			repeat += " " + r.Feb29
+		}
+	case KindRRule:
+		repeat = r.RRule
+
+		if rule, err := ParseRRule(r.RRule); err == nil {
+			repeat = rule.String()
+		}
+	default:
+		repeat = r.Kind
+	}
+
+	if r.Shift {
+		repeat += " " + shiftModifier
+	}
+
+	return repeat
+}
+
+// compareDays упорядочивает номера так, что отрицательные (отсчет с конца) идут после положительных.
+func compareDays(a int, b int) int {
+	if (a < 0) != (b < 0) {
+		return cmp.Compare(b, a)
+	}
+
+	return cmp.Compare(a, b)
+}
+
+// sortedInts возвращает упорядоченную копию списка без повторов.
+func sortedInts(nums []int) []int {
+	nums = slices.Clone(nums)
+	slices.SortFunc(nums, compareDays)
+
+	return slices.Compact(nums)
+}
+
+// joinInts записывает числа через запятую.
+func joinInts(nums []int) string {
+	items := make([]string, 0, len(nums))
+
+	for _, e := range nums {
+		items = append(items, strconv.Itoa(e))
+	}
+
+	return strings.Join(items, ",")
+}
+
+// monthsSuffix записывает необязательный список месяцев третьей частью правила.
+func monthsSuffix(months []int) string {
+	if len(months) == 0 {
+		return ""
+	}
+
+	return " " + joinInts(sortedInts(months))
+}
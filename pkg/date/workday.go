@@ -0,0 +1,121 @@
+package date
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// maxShiftSteps ограничивает число попыток найти дату после переноса на рабочий день.
+	maxShiftSteps  = 100
+	workdaysInWeek = 5
+)
+
+// isWorkday проверяет, что дата не выпадает на выходной или праздничный день.
+func isWorkday(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+
+	return !holidays[date.Format(dateFormat)]
+}
+
+// toWorkday переносит дату на ближайший рабочий день, начиная с нее самой.
+func toWorkday(date time.Time) (time.Time, error) {
+	for end := date.AddDate(searchYears, 0, 0); date.Before(end); date = date.AddDate(0, 0, 1) {
+		if isWorkday(date) {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w", errRule)
+}
+
+// businessDayRule проверяет правило повторения через указанное количество рабочих дней.
+// Если дата задачи в прошлом, пройденные серии рабочих дней пропускаются сразу до сегодняшнего дня.
+func businessDayRule(now time.Time, date time.Time, days int) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())
+	steps := days
+
+	if date.Before(today) {
+		passed := workdaysBetween(date, today)
+		steps = (passed/days+1)*days - passed
+		date = today
+	}
+
+	var err error
+
+	for {
+		for i := 0; i < steps; i++ {
+			date, err = toWorkday(date.AddDate(0, 0, 1))
+			if err != nil {
+				return time.Time{}, err
+			}
+		}
+
+		if date.After(now) {
+			return date, nil
+		}
+
+		steps = days
+	}
+}
+
+// workdaysBetween возвращает число рабочих дней после from до to включительно.
+func workdaysBetween(from time.Time, to time.Time) int {
+	total := daysBetween(from, to)
+	weeks := total / daysInWeek
+	count := weeks * workdaysInWeek
+
+	for i := weeks*daysInWeek + 1; i <= total; i++ {
+		if day := from.AddDate(0, 0, i).Weekday(); day != time.Saturday && day != time.Sunday {
+			count++
+		}
+	}
+
+	fromKey, toKey := from.Format(dateFormat), to.Format(dateFormat)
+
+	for day := range holidays {
+		if day <= fromKey || day > toKey {
+			continue
+		}
+
+		if date, err := time.Parse(dateFormat, day); err == nil && date.Weekday() != time.Saturday &&
+			date.Weekday() != time.Sunday {
+			count--
+		}
+	}
+
+	return count
+}
+
+// shiftRule вычисляет дату по основному правилу и переносит ее на ближайший рабочий день.
+// Дата задачи могла быть уже перенесена, поэтому отсчет ведется от начала предшествующих ей нерабочих дней,
+// чтобы перенос не накапливался от повторения к повторению.
+func shiftRule(now time.Time, date time.Time, base Rule) (time.Time, error) {
+	anchor := date
+
+	for i := 0; i < maxShiftSteps && !isWorkday(anchor.AddDate(0, 0, -1)); i++ {
+		anchor = anchor.AddDate(0, 0, -1)
+	}
+
+	for i := 0; i < maxShiftSteps; i++ {
+		next, err := base.next(now, anchor)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		shifted, err := toWorkday(next)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if shifted.After(date) {
+			return shifted, nil
+		}
+
+		anchor = next
+	}
+
+	return time.Time{}, fmt.Errorf("%w", errRule)
+}
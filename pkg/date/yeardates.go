@@ -0,0 +1,161 @@
+package date
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Способы обработки 29 февраля в невисокосные годы для правила y: для указанных дат
+// или, если даты не указаны, для даты задачи.
+const (
+	Feb29Mar1  = "mar1"
+	Feb29Feb28 = "feb28"
+	Feb29Skip  = "skip"
+)
+
+// YearDate день года в правиле y: месяц и число.
+type YearDate struct {
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// parseYearDates разбирает список дат вида 03-08,09-01.
+func parseYearDates(value string) ([]YearDate, error) {
+	parts := strings.Split(value, ",")
+	dates := make([]YearDate, 0, len(parts))
+
+	for _, e := range parts {
+		// 2000 — високосный год, поэтому 29 февраля разбирается как допустимая дата.
+		day, err := time.Parse("2006-01-02", "2000-"+e)
+		if err != nil {
+			return nil, fmt.Errorf("%w", errDate)
+		}
+
+		dates = append(dates, YearDate{Month: int(day.Month()), Day: day.Day()})
+	}
+
+	return dates, nil
+}
+
+// validateYearDates проверяет даты правила y и способ обработки 29 февраля.
+func validateYearDates(dates []YearDate, feb29 string) error {
+	if feb29 != "" && !isFeb29Policy(feb29) {
+		return fmt.Errorf("%w", errRule)
+	}
+
+	for _, e := range dates {
+		if e.Month < minMonth || e.Month > maxMonth || e.Day < minMDay || e.Day > monthDays(e.Month) {
+			return fmt.Errorf("%w", errDate)
+		}
+	}
+
+	return nil
+}
+
+// isFeb29Policy проверяет, что значение — способ обработки 29 февраля.
+func isFeb29Policy(value string) bool {
+	return value == Feb29Mar1 || value == Feb29Feb28 || value == Feb29Skip
+}
+
+// yearDatesRule ищет ближайшую из указанных дат года после даты задачи и текущей даты.
+func yearDatesRule(now time.Time, date time.Time, dates []YearDate, feb29 string) (time.Time, error) {
+	after := date
+
+	if now.After(after) {
+		after = now
+	}
+
+	for year := after.Year(); year <= after.Year()+searchYears; year++ {
+		var candidates []time.Time
+
+		for _, e := range dates {
+			if day, ok := yearDate(year, e, feb29, date.Location()); ok && day.After(after) {
+				candidates = append(candidates, day)
+			}
+		}
+
+		if len(candidates) > 0 {
+			return slices.MinFunc(candidates, func(a, b time.Time) int { return a.Compare(b) }), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w", errRule)
+}
+
+// yearDate возвращает дату в указанном году с учетом способа обработки 29 февраля.
+func yearDate(year int, date YearDate, feb29 string, loc *time.Location) (time.Time, bool) {
+	day := time.Date(year, time.Month(date.Month), date.Day, 0, 0, 0, 0, loc)
+
+	if date.Month != int(time.February) || date.Day != 29 || day.Month() == time.February {
+		return day, true
+	}
+
+	switch feb29 {
+	case Feb29Feb28:
+		return day.AddDate(0, 0, -1), true
+	case Feb29Skip:
+		return time.Time{}, false
+	default:
+		return day, true
+	}
+}
+
+// sortedYearDates возвращает даты года по порядку и без повторов.
+func sortedYearDates(dates []YearDate) []YearDate {
+	dates = slices.Clone(dates)
+	slices.SortFunc(dates, func(a, b YearDate) int {
+		if a.Month != b.Month {
+			return a.Month - b.Month
+		}
+
+		return a.Day - b.Day
+	})
+
+	return slices.Compact(dates)
+}
+
+// yearDatesString записывает даты правила y в каноническом виде: 03-08,09-01.
+func yearDatesString(dates []YearDate) string {
+	items := make([]string, 0, len(dates))
+
+	for _, e := range sortedYearDates(dates) {
+		items = append(items, fmt.Sprintf("%02d-%02d", e.Month, e.Day))
+	}
+
+	return strings.Join(items, ",")
+}
+
+// describeYearDates описывает правило y с датами: «каждый год 8 марта и 1 сентября».
+func describeYearDates(dates []YearDate, feb29 string, en bool) string {
+	dates = sortedYearDates(dates)
+	items := make([]string, 0, len(dates))
+	leap := false
+
+	for _, e := range dates {
+		leap = leap || e.Month == int(time.February) && e.Day == 29
+		items = append(items, pick(en, fmt.Sprintf("%s %d", time.Month(e.Month), e.Day),
+			fmt.Sprintf("%d %s", e.Day, ruMonthsGen[e.Month-1])))
+	}
+
+	description := pick(en, "every year on ", "каждый год ") + joinList(items, en)
+
+	if !leap {
+		return description
+	}
+
+	return description + describeFeb29(feb29, en)
+}
+
+// describeFeb29 описывает способ обработки 29 февраля в невисокосные годы.
+func describeFeb29(feb29 string, en bool) string {
+	switch feb29 {
+	case Feb29Feb28:
+		return pick(en, " (February 28 in non-leap years)", " (28 февраля в невисокосные годы)")
+	case Feb29Skip:
+		return pick(en, " (February 29 only in leap years)", " (29 февраля только в високосные годы)")
+	default:
+		return pick(en, " (March 1 in non-leap years)", " (1 марта в невисокосные годы)")
+	}
+}
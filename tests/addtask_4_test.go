@@ -165,6 +165,8 @@ func TestAddTask(t *testing.T) {
 	if FullNextDate {
 		tbl = []task{
 			{"20240129", "Сходить в магазин", "", "w 1,3,5"},
+			{"20240129", "Оплатить интернет", "", "m 1,15"},
+			{"20240129", "Сдать отчет", "", "m -1 3,6,9,12"},
 		}
 		check()
 	}
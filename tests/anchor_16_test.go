@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchor(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	for _, v := range []map[string]any{
+		{"title": "Задача", "repeat": "d 7", "anchor": "sometimes"},
+		{"title": "Задача", "anchor": "completion"},
+		{"title": "Задача", "repeat": "FREQ=DAILY;COUNT=3", "anchor": "completion"},
+	} {
+		m, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", v)
+	}
+
+	for _, v := range []struct {
+		anchor string
+		done   string
+		update string
+	}{
+		{"", day(12), day(4)},
+		{"calendar", day(12), day(4)},
+		{"completion", day(7), day(0)},
+	} {
+		m, err := postJSON("api/task", map[string]any{
+			"date":   day(5),
+			"title":  "Полить цветы",
+			"repeat": "d 7",
+			"anchor": v.anchor,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		id := fmt.Sprint(m["id"])
+		assert.Equal(t, v.anchor, getTask(t, id)["anchor"])
+
+		_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Equal(t, v.done, getTask(t, id)["date"], v.anchor)
+
+		m, err = postJSON("api/task", map[string]any{
+			"id":     id,
+			"date":   day(-3),
+			"title":  "Полить цветы",
+			"repeat": "d 7",
+			"anchor": v.anchor,
+		}, http.MethodPut)
+		assert.NoError(t, err)
+		assert.Empty(t, m["error"])
+		task := getTask(t, id)
+		assert.Equal(t, v.update, task["date"], v.anchor)
+		assert.Equal(t, v.anchor, task["anchor"])
+
+		_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronDates(t *testing.T) {
+	tbl := []struct {
+		now    string
+		repeat string
+		want   []string
+	}{
+		{"20240126 09:30", "cron 0 9 * * 1-5", []string{"20240129 09:00", "20240130 09:00", "20240131 09:00"}},
+		{"20240126 08:00", "cron 0 9 * * MON-FRI", []string{"20240126 09:00", "20240129 09:00", "20240130 09:00"}},
+		{"20240126 23:50", "cron */15 * * * *", []string{"20240127 00:00", "20240127 00:15", "20240127 00:30"}},
+		{"20240126", "cron 0 0 31 * *", []string{"20240131 00:00", "20240331 00:00", "20240531 00:00"}},
+		{"20240126", "cron 0 12 13 * 5", []string{"20240126 12:00", "20240202 12:00", "20240209 12:00"}},
+		{"20240126", "cron 30 6 1 jan,jul 0", []string{"20240128 06:30", "20240701 06:30", "20240707 06:30"}},
+		{"20240126", "cron 0 8 29 2 *", []string{"20240229 08:00", "20280229 08:00", "20320229 08:00"}},
+	}
+	for _, v := range tbl {
+		body, err := getBody(fmt.Sprintf("api/nextdates?now=%s&date=20240126&repeat=%s&count=3",
+			url.QueryEscape(v.now), url.QueryEscape(v.repeat)))
+		assert.NoError(t, err)
+
+		var dates map[string][]string
+		assert.NoError(t, json.Unmarshal(body, &dates))
+		assert.Equal(t, v.want, dates["dates"], v.repeat)
+	}
+}
+
+func TestCronErrors(t *testing.T) {
+	today := time.Now().Format(`20060102`)
+
+	tbl := []struct {
+		repeat string
+		field  string
+	}{
+		{"cron 60 9 * * *", "минуты"},
+		{"cron 0 24 * * *", "часы"},
+		{"cron 0 9 0 * *", "день месяца"},
+		{"cron 0 9 * 13 *", "месяц"},
+		{"cron 0 9 31 2 *", "существующей даты"},
+		{"cron 0 9 30,31 2 *", "существующей даты"},
+		{"cron 0 9 * * 8", "день недели"},
+		{"cron 0 9 * * 5-1", "день недели"},
+		{"cron 0 9 * * MON/0", "день недели"},
+		{"cron 0 9 * *", "5 полей"},
+		{"cron 0 9 * * * *", "5 полей"},
+		{"cron 0 9 * * 1-5 shift", ""},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/task", map[string]any{
+			"date":   today,
+			"title":  "Резервная копия",
+			"repeat": v.repeat,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для правила %s", v.repeat)
+		assert.Contains(t, fmt.Sprint(e), v.field, v.repeat)
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		ru     string
+		en     string
+	}{
+		{"d 1", "каждый день", "every day"},
+		{"d 7", "каждые 7 дней", "every 7 days"},
+		{"d 3", "каждые 3 дня", "every 3 days"},
+		{"y", "каждый год", "every year"},
+		{"w 1,4", "по понедельникам и четвергам", "every Monday and Thursday"},
+		{"m 1,15", "1 и 15 числа каждого месяца", "on the 1st and 15th of every month"},
+		{"wm 2:2", "во второй вторник каждого месяца", "on the second Tuesday of every month"},
+		{"bd 1", "каждый рабочий день", "every business day"},
+		{"x", "", ""},
+		{"d 0", "", ""},
+		{"", "", ""},
+	}
+	for _, v := range tbl {
+		for lang, want := range map[string]string{"ru": v.ru, "en": v.en} {
+			body, err := getBody("api/describe?lang=" + lang + "&repeat=" + url.QueryEscape(v.repeat))
+			assert.NoError(t, err)
+
+			var m map[string]string
+			assert.NoError(t, json.Unmarshal(body, &m))
+
+			if want == "" {
+				assert.NotEmpty(t, m["error"], v.repeat)
+				continue
+			}
+			assert.Equal(t, want, m["description"], v.repeat)
+		}
+	}
+}
+
+func TestTaskDescription(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:   "20240126",
+		title:  "Описание правила",
+		repeat: "d 7",
+	})
+
+	m := getTask(t, id)
+	assert.Equal(t, "каждые 7 дней", m["repeat_description"])
+}
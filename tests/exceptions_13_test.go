@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getExceptions(t *testing.T, id string) []string {
+	body, err := requestJSON("api/task/exceptions?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m["exceptions"]
+}
+
+func TestExceptions(t *testing.T) {
+	now := time.Now()
+	day := func(days int) string {
+		return now.AddDate(0, 0, days).Format(`20060102`)
+	}
+
+	single := addTask(t, task{date: day(0), title: "Разовая задача"})
+	ret, err := postJSON("api/task/exceptions?id="+single+"&date="+day(1), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	id := addTask(t, task{date: day(0), title: "Спортзал", repeat: "d 7"})
+
+	ret, err = postJSON("api/task/exceptions?id="+id+"&date=01.02.2024", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/exceptions?id="+id+"&date="+day(7), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{day(7)}, getExceptions(t, id))
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(14), getTask(t, id)["date"])
+
+	ret, err = postJSON("api/task/exceptions?id="+id+"&date="+day(14), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(21), getTask(t, id)["date"])
+	assert.Equal(t, []string{day(7), day(14)}, getExceptions(t, id))
+
+	ret, err = postJSON("api/task/exceptions?id="+id+"&date="+day(7), nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{day(14)}, getExceptions(t, id))
+
+	ret, err = postJSON("api/task/exceptions?id="+id+"&date="+day(7), nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Contains(t, fmt.Sprint(ret["error"]), "не найдена", fmt.Sprint(ret))
+	assert.NotContains(t, fmt.Sprint(ret["error"]), "%!", fmt.Sprint(ret))
+
+	for _, query := range []string{
+		"id=" + id + "&date=01.02.2024",
+		"id=" + id,
+		"id=&date=" + day(14),
+		"id=abc&date=" + day(14),
+		"id=999999999&date=" + day(14),
+	} {
+		ret, err = postJSON("api/task/exceptions?"+query, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], query)
+	}
+	assert.Equal(t, []string{day(14)}, getExceptions(t, id))
+}
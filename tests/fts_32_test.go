@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// skipWithoutFTS пропускает тест, если сервер собран без FTS5 и ищет задачи через LIKE:
+// без полнотекстового индекса сервер удаляет его триггеры.
+func skipWithoutFTS(t *testing.T, db *sqlx.DB) {
+	var triggers int
+	err := db.Get(&triggers, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'scheduler_search_insert'")
+	assert.NoError(t, err)
+	if triggers == 0 {
+		t.Skip("сервер собран без тега sqlite_fts5")
+	}
+}
+
+func TestSearchRank(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+	skipWithoutFTS(t, db)
+
+	commentID := addTask(t, task{
+		date:    "20240126",
+		title:   "Купить горшок",
+		comment: "Для кактуса и фикуса",
+	})
+	titleID := addTask(t, task{
+		date:  "20240127",
+		title: "Полить кактус",
+	})
+
+	tasks := getTasks(t, url.QueryEscape("кактусы"))
+	if !assert.Equal(t, 2, len(tasks)) {
+		return
+	}
+	assert.Equal(t, titleID, tasks[0]["id"])
+	assert.Equal(t, "Полить <mark>кактус</mark>", tasks[0]["highlight"])
+	assert.Empty(t, tasks[0]["snippet"])
+	assert.Equal(t, commentID, tasks[1]["id"])
+	assert.Equal(t, "Для <mark>кактуса</mark> и фикуса", tasks[1]["snippet"])
+
+	_, err := db.Exec("UPDATE scheduler SET title = ? WHERE id = ?", "Полить фикус", titleID)
+	assert.NoError(t, err)
+	tasks = getTasks(t, url.QueryEscape("кактус"))
+	assert.Equal(t, 1, len(tasks))
+
+	for _, v := range []struct {
+		search string
+		want   int
+	}{
+		{"фикусы", 2},
+		{"горшок фикуса", 1},
+		{"горшок полить", 0},
+		{"%", 0},
+	} {
+		tasks := getTasks(t, url.QueryEscape(v.search))
+		assert.Equal(t, v.want, len(tasks), v.search)
+	}
+}
+
+func TestSearchWords(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+	skipWithoutFTS(t, db)
+
+	addTask(t, task{
+		date:    "20240126",
+		title:   "Отнести посылку",
+		comment: "Почта закрывается в 19:00",
+	})
+
+	for _, v := range []struct {
+		search string
+		want   int
+	}{
+		{"посылки", 1},
+		{"ПОЧТУ посылку", 1},
+		{"сылк", 0},
+		{"посылку магазин", 0},
+	} {
+		tasks := getTasks(t, url.QueryEscape(v.search))
+		assert.Equal(t, v.want, len(tasks), v.search)
+	}
+}
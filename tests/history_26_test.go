@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getHistory(t *testing.T, id string) []map[string]string {
+	body, err := requestJSON("api/task/history?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m["history"]
+}
+
+func TestHistory(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+
+	id := addTask(t, task{
+		date:   today,
+		title:  "Полить цветы",
+		repeat: "d 7",
+	})
+	assert.Empty(t, getHistory(t, id))
+
+	_, err := postJSON("api/task/done?id="+id, map[string]any{"note": "Полил все, кроме кактуса"}, http.MethodPost)
+	assert.NoError(t, err)
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+
+	history := getHistory(t, id)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, id, history[0]["task_id"])
+		assert.Equal(t, today, history[0]["date"])
+		assert.Equal(t, "Полил все, кроме кактуса", history[0]["note"])
+		assert.Equal(t, now.AddDate(0, 0, 7).Format(`20060102`), history[1]["date"])
+		assert.Empty(t, history[1]["note"])
+
+		doneAt, err := time.Parse(time.RFC3339, history[1]["done_at"])
+		assert.NoError(t, err)
+		assert.WithinDuration(t, now, doneAt, time.Minute)
+	}
+
+	id = addTask(t, task{
+		date:  today,
+		title: "Купить хлеб",
+	})
+	_, err = postJSON("api/task/done?id="+id, map[string]any{"note": "Бородинский"}, http.MethodPost)
+	assert.NoError(t, err)
+	notFoundTask(t, id)
+
+	history = getHistory(t, id)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, today, history[0]["date"])
+		assert.Equal(t, "Бородинский", history[0]["note"])
+	}
+
+	for _, v := range []string{"", "abc"} {
+		m, err := postJSON("api/task/history?id="+v, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], v)
+	}
+}
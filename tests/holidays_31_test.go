@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Memonagi/go_final_project/pkg/date"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const holidaysICS = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Каникулы\r\n" +
+	"DTSTART;VALUE=DATE:20240129\r\n" +
+	"DTEND;VALUE=DATE:20240131\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Ежегодный праздник\r\n" +
+	"DTSTART;VALUE=DATE:20220212\r\n" +
+	"RRULE:FREQ=YEA\r\n" +
+	" RLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Третий понедельник февраля\r\n" +
+	"DTSTART;VALUE=DATE:20230220\r\n" +
+	"RRULE:FREQ=YEARLY;BYMONTH=2;BYDAY=3MO\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+// loadHolidays записывает календарь праздников во временный файл и загружает его;
+// после теста календарь очищается.
+func loadHolidays(t *testing.T, data string) error {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "holidays")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	t.Cleanup(func() {
+		empty := filepath.Join(dir, "empty")
+		require.NoError(t, os.WriteFile(empty, nil, 0o600))
+		require.NoError(t, date.LoadHolidays(empty))
+	})
+
+	return date.LoadHolidays(path)
+}
+
+func checkHolidays(t *testing.T, tbl []nextDate) {
+	t.Helper()
+
+	for _, v := range tbl {
+		now, err := time.Parse("20060102", v.date)
+		require.NoError(t, err)
+		next, err := date.NextDate(now, v.date, v.repeat)
+		assert.NoError(t, err, v.repeat)
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`, v.date, v.repeat, v.want)
+	}
+}
+
+func TestHolidaysList(t *testing.T) {
+	require.NoError(t, loadHolidays(t, "# Каникулы\n20240129\n\n2024-01-30\n"))
+
+	checkHolidays(t, []nextDate{
+		{"20240126", "bd 1", "20240131"},
+		{"20240126", "d 1 shift", "20240131"},
+		{"20240131", "bd 1", "20240201"},
+	})
+}
+
+func TestHolidaysICS(t *testing.T) {
+	require.NoError(t, loadHolidays(t, holidaysICS))
+
+	checkHolidays(t, []nextDate{
+		{"20240126", "bd 1", "20240131"},
+		{"20240126", "d 1 shift", "20240131"},
+		{"20240209", "bd 1", "20240213"},
+		{"20240211", "d 1 shift", "20240213"},
+		{"20240216", "bd 1", "20240220"},
+		{"20250211", "d 1 shift", "20250213"},
+		{"20250214", "bd 1", "20250218"},
+	})
+}
+
+func TestHolidaysErrors(t *testing.T) {
+	for _, v := range []string{
+		"20240129\n2024-13-01\n",
+		"20240129\n29.01.2024\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20240129\nRRULE:FREQ=WEEKLY\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20240129\nRRULE:FREQ=YEARLY;BYDAY=XX\nEND:VEVENT\nEND:VCALENDAR\n",
+	} {
+		assert.Error(t, loadHolidays(t, v), v)
+	}
+
+	assert.Error(t, date.LoadHolidays(filepath.Join(t.TempDir(), "missing.ics")))
+}
+
+// TestHolidaysEnv запускает отдельный сервер с календарем из TODO_HOLIDAYS.
+func TestHolidaysEnv(t *testing.T) {
+	if testing.Short() {
+		t.Skip("сервер с календарем праздников не запускается в режиме -short")
+	}
+
+	dir := t.TempDir()
+	holidaysFile := filepath.Join(dir, "holidays.ics")
+	require.NoError(t, os.WriteFile(holidaysFile, []byte(holidaysICS), 0o600))
+
+	server := filepath.Join(dir, "scheduler-service")
+	build := exec.Command("go", "build", "-tags", "sqlite_fts5", "-o", server, "../cmd/scheduler-service")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	cmd := exec.Command(server)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("TODO_PORT=%d", port),
+		"TODO_DBFILE="+filepath.Join(dir, "scheduler.db"),
+		"TODO_HOLIDAYS="+holidaysFile,
+	)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	url := fmt.Sprintf("http://localhost:%d/api/nextdate?now=20240209&date=20240209&repeat=bd+1", port)
+
+	var body string
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false
+		}
+		body = strings.TrimSpace(string(data))
+
+		return true
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, "20240213", body)
+}
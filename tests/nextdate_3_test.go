@@ -67,6 +67,10 @@ func TestNextDate(t *testing.T) {
 		{"20230311", "m 1 1,2", "20240201"},
 		{"20240127", "m -1", "20240131"},
 		{"20240222", "m -2", "20240228"},
+		{"20240126", "m 30 2", ""},
+		{"20240126", "m 31 4,6,9,11", ""},
+		{"20240126", "m 29 2", "20240229"},
+		{"20240126", "m 30,31 2,4", "20240430"},
 		{"20240222", "m -2,-3", ""},
 		{"20240326", "m -1,-2", "20240330"},
 		{"20240201", "m -1,18", "20240218"},
@@ -0,0 +1,26 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Memonagi/go_final_project/pkg/date"
+)
+
+// BenchmarkNextDate сравнивает время вычисления для близкой и очень далекой даты задачи:
+// при вычислении без перебора оно не зависит от того, сколько повторений пропущено.
+func BenchmarkNextDate(b *testing.B) {
+	now := time.Date(2024, time.January, 26, 12, 0, 0, 0, time.UTC)
+
+	for _, repeat := range []string{"d 1", "d 7", "w 1,4", "y", "bd 3"} {
+		for _, start := range []string{"20240120", "19240126", "16890220"} {
+			b.Run(repeat+"/"+start, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := date.NextDate(now, start, repeat); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
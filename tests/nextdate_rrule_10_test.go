@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateRRule(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "FREQ=HOURLY", ""},
+		{"20240126", "FREQ=DAILY;BYDAY=1MO", ""},
+		{"20240126", "FREQ=DAILY;COUNT=2;UNTIL=20240101", ""},
+		{"20240126", "FREQ=WEEKLY;FOO=1", ""},
+		{"20240126", "FREQ=WEEKLY;BYDAY=XX", ""},
+		{"20240126", "FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"20240126", "FREQ=DAILY;INTERVAL=0", ""},
+		{"20240126", "INTERVAL=2", ""},
+		{"20240120", "FREQ=DAILY", "20240127"},
+		{"20240120", "FREQ=DAILY;INTERVAL=3", "20240129"},
+		{"20240101", "FREQ=WEEKLY;BYDAY=MO,TH", "20240129"},
+		{"20240102", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", "20240130"},
+		{"20240101", "FREQ=MONTHLY;BYMONTHDAY=-1", "20240131"},
+		{"20240101", "FREQ=MONTHLY;BYDAY=-1FR", "20240223"},
+		{"20240101", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "20240131"},
+		{"20240101", "FREQ=YEARLY;BYMONTH=3;BYDAY=2SU", "20240310"},
+		{"20230215", "RRULE:FREQ=YEARLY", "20240215"},
+		{"20231110", "FREQ=MONTHLY;COUNT=3", ""},
+		{"20231110", "FREQ=MONTHLY;COUNT=4", "20240210"},
+		{"20240101", "FREQ=DAILY;UNTIL=20240125", ""},
+		{"20240101", "FREQ=DAILY;UNTIL=20240131T000000Z", "20240127"},
+		{"20240409", "FREQ=MONTHLY;BYMONTHDAY=31", "20240531"},
+		{"20240301", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", "20280229"},
+		{"20240101", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", ""},
+		{"20240101", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-30", ""},
+		{"16000101", "FREQ=DAILY;INTERVAL=7", "20240127"},
+		{"16000103", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", "20240205"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
+
+func TestDoneRRuleCount(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Курс таблеток",
+		repeat: "FREQ=DAILY;COUNT=2",
+	})
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), task.Date)
+	assert.Equal(t, "FREQ=DAILY;COUNT=1", task.Repeat)
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+}
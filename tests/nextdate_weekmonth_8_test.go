@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateWeekMonth(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "wm", ""},
+		{"20240101", "wm 2", ""},
+		{"20240101", "wm 2:", ""},
+		{"20240101", "wm 0:1", ""},
+		{"20240101", "wm 6:1", ""},
+		{"20240101", "wm -2:1", ""},
+		{"20240101", "wm 2:8", ""},
+		{"20240101", "wm 2:2 13", ""},
+		{"20240101", "wm 2:2 1 1", ""},
+		{"20240101", "wm 2:2", "20240213"},
+		{"20240101", "wm -1:5", "20240223"},
+		{"20240126", "wm 1:1,3:3", "20240205"},
+		{"20240126", "wm 5:4", "20240229"},
+		{"20240101", "wm 5:1", "20240129"},
+		{"20240301", "wm 1:7", "20240303"},
+		{"20240126", "wm -1:7", "20240128"},
+		{"20240101", "wm -1:5 3,6,9,12", "20240329"},
+		{"20230101", "wm 2:2 1", "20250114"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
+
+func TestAddTaskWeekMonth(t *testing.T) {
+	tbl := []task{
+		{"20240129", "Планерка", "", "wm 0:2"},
+		{"20240129", "Планерка", "", "wm 2:9"},
+		{"20240129", "Планерка", "", "wm 2-2"},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/task", map[string]any{
+			"date":    v.date,
+			"title":   v.title,
+			"comment": v.comment,
+			"repeat":  v.repeat,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0,
+			"Ожидается ошибка для задачи %v", v)
+	}
+
+	id := addTask(t, task{
+		date:   time.Now().Format(`20060102`),
+		title:  "Зарплата",
+		repeat: "wm 2:2,-1:5",
+	})
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
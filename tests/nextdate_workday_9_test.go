@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateWorkday(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "bd", ""},
+		{"20240126", "bd 0", ""},
+		{"20240126", "bd 401", ""},
+		{"20240126", "shift", ""},
+		{"20240126", "k 1 shift", ""},
+		{"20240126", "d shift", ""},
+		{"20240126", "bd 1", "20240129"},
+		{"20240122", "bd 3", "20240130"},
+		{"20240101", "bd 5", "20240129"},
+		{"20240126", "d 1 shift", "20240129"},
+		{"20240120", "d 7 shift", "20240129"},
+		{"20240129", "d 1 shift", "20240130"},
+		{"20230128", "y shift", "20240129"},
+		{"20230107", "y shift", "20250107"},
+		{"20240126", "w 6,7 shift", "20240129"},
+		{"20240129", "m 27 shift", "20240227"},
+		{"20240101", "wm -1:6 shift", "20240129"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDates(t *testing.T) {
+	tbl := []struct {
+		query string
+		want  []string
+	}{
+		{"date=20240126&repeat=m+-1&count=3", []string{"20240131", "20240229", "20240331"}},
+		{"date=20240126&repeat=d+7&until=20240301",
+			[]string{"20240202", "20240209", "20240216", "20240223", "20240301"}},
+		{"date=20231110&repeat=" + url.QueryEscape("FREQ=MONTHLY;COUNT=5"), []string{"20240210", "20240310"}},
+		{"date=20240126&repeat=w+1,5&count=4", []string{"20240129", "20240202", "20240205", "20240209"}},
+		{"date=20240101&repeat=y&count=2", []string{"20250101", "20260101"}},
+		{"date=20240126&repeat=d+1&until=20240126", []string{}},
+		{"date=20240126&repeat=d+7&count=0", nil},
+		{"date=20240126&repeat=d+7&count=101", nil},
+		{"date=20240126&repeat=d+7&count=abc", nil},
+		{"date=20240126&repeat=d+7&until=2024-03-01", nil},
+		{"date=20240126&repeat=ooops", nil},
+	}
+	for _, v := range tbl {
+		body, err := getBody("api/nextdates?now=20240126&" + v.query)
+		assert.NoError(t, err)
+
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+
+		if v.want == nil {
+			assert.NotEmpty(t, m["error"], v.query)
+			continue
+		}
+
+		var dates map[string][]string
+		assert.NoError(t, json.Unmarshal(body, &dates))
+		assert.Equal(t, v.want, dates["dates"], v.query)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTasksQuery(t *testing.T, query string) []map[string]string {
+	body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m["tasks"]
+}
+
+func TestPriority(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	const date = "20310315"
+
+	ids := make(map[string]string)
+	for _, v := range []struct {
+		title    string
+		priority string
+	}{
+		{"Без приоритета", ""},
+		{"Низкий", "3"},
+		{"Срочный", "1"},
+		{"Важный", "2"},
+	} {
+		values := map[string]any{"date": date, "title": v.title}
+		if v.priority != "" {
+			values["priority"] = v.priority
+		}
+		ret, err := postJSON("api/task", values, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret["error"], v.title)
+		ids[v.title] = ret["id"].(string)
+		assert.Equal(t, v.priority, getTask(t, ids[v.title])["priority"], v.title)
+	}
+
+	tasks := getTasksQuery(t, "search=15.03.2031")
+	titles := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		titles = append(titles, task["title"])
+	}
+	assert.Equal(t, []string{"Срочный", "Важный", "Низкий", "Без приоритета"}, titles)
+
+	tasks = getTasksQuery(t, "search=15.03.2031&priority=1,2")
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, ids["Срочный"], tasks[0]["id"])
+		assert.Equal(t, ids["Важный"], tasks[1]["id"])
+	}
+
+	tasks = getTasksQuery(t, "search=15.03.2031&priority=4")
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, ids["Без приоритета"], tasks[0]["id"])
+	}
+
+	for _, v := range []struct {
+		title    string
+		time     string
+		priority string
+	}{
+		{"Вечером срочно", "18:00", "1"},
+		{"Утром не срочно", "09:00", "4"},
+		{"Утром срочно", "09:00", "1"},
+	} {
+		ret, err := postJSON("api/task", map[string]any{
+			"date":     "20310316",
+			"title":    v.title,
+			"time":     v.time,
+			"priority": v.priority,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret["error"], v.title)
+	}
+
+	titles = titles[:0]
+	for _, task := range getTasksQuery(t, "search=16.03.2031") {
+		titles = append(titles, task["title"])
+	}
+	assert.Equal(t, []string{"Утром срочно", "Утром не срочно", "Вечером срочно"}, titles)
+
+	ret, err := postJSON("api/task", map[string]any{
+		"id":       ids["Низкий"],
+		"date":     date,
+		"title":    "Низкий",
+		"priority": "1",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	assert.Equal(t, "1", getTask(t, ids["Низкий"])["priority"])
+
+	for _, v := range []string{"0", "5", "abc", "1.5"} {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": "Ошибка", "priority": v}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], v)
+	}
+
+	for _, v := range []string{"0", "1,x", "5"} {
+		body, err := requestJSON("api/tasks?priority="+v, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.NotEmpty(t, m["error"], v)
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addProject(t *testing.T, name string) string {
+	ret, err := postJSON("api/project", map[string]any{"name": name}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"], name)
+	id, _ := ret["id"].(string)
+	assert.NotEmpty(t, id, name)
+	return id
+}
+
+func getProjects(t *testing.T) []map[string]any {
+	body, err := requestJSON("api/projects", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m["projects"]
+}
+
+func projectTasks(t *testing.T, query string) []string {
+	body, err := requestJSON("api/tasks?search=25.05.2031&"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+
+	var titles []string
+	for _, task := range m["tasks"] {
+		titles = append(titles, task["title"])
+	}
+	return titles
+}
+
+func TestProjects(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	const date = "20310525"
+
+	work := addProject(t, "Работа")
+	home := addProject(t, "Дом")
+
+	for _, name := range []string{"", "   ", "Работа"} {
+		ret, err := postJSON("api/project", map[string]any{"name": name}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], name)
+	}
+
+	ids := make(map[string]string)
+	for _, v := range []struct {
+		title   string
+		project string
+	}{
+		{"Отчет", work},
+		{"Созвон", work},
+		{"Починить кран", home},
+		{"Без проекта", ""},
+	} {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": v.title, "project_id": v.project}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret["error"], v.title)
+		ids[v.title], _ = ret["id"].(string)
+		assert.Equal(t, v.project, getTask(t, ids[v.title])["project_id"], v.title)
+	}
+
+	for _, v := range []string{"999999", "abc"} {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": "Ошибка", "project_id": v}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], v)
+	}
+
+	assert.ElementsMatch(t, []string{"Отчет", "Созвон"}, projectTasks(t, "project="+work))
+	assert.ElementsMatch(t, []string{"Без проекта"}, projectTasks(t, "project=inbox"))
+
+	m, err := postJSON("api/tasks?project=abc", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+
+	projects := getProjects(t)
+	if assert.Len(t, projects, 2) {
+		assert.Equal(t, "Дом", projects[0]["name"])
+		assert.Equal(t, float64(1), projects[0]["count"])
+		assert.Equal(t, "Работа", projects[1]["name"])
+		assert.Equal(t, float64(2), projects[1]["count"])
+	}
+
+	ret, err := postJSON("api/project", map[string]any{"id": home, "name": "Покупки"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Equal(t, "Покупки", ret["name"])
+	ret, err = postJSON("api/project?id="+home, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "Покупки", ret["name"])
+
+	ret, err = postJSON("api/project?id="+work+"&mode=archive", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/project?id="+home, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	assert.Empty(t, getTask(t, ids["Починить кран"])["project_id"])
+	assert.ElementsMatch(t, []string{"Починить кран", "Без проекта"}, projectTasks(t, "project=inbox"))
+
+	ret, err = postJSON("api/project?id="+work+"&mode=cascade", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	notFoundTask(t, ids["Отчет"])
+	notFoundTask(t, ids["Созвон"])
+	assert.Contains(t, getTrash(t), ids["Отчет"])
+	assert.Empty(t, getProjects(t))
+
+	for _, id := range []string{work, "", "abc"} {
+		ret, err = postJSON("api/project?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], id)
+		ret, err = postJSON("api/project?id="+id, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], id)
+	}
+}
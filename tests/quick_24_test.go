@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuickAdd(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+	tomorrow := now.AddDate(0, 0, 1).Format(`20060102`)
+	ahead := (int(time.Friday)-int(now.Weekday())+6)%7 + 1
+	friday := now.AddDate(0, 0, ahead).Format(`20060102`)
+	// first возвращает первую, начиная с сегодняшней, дату, подходящую под условие.
+	first := func(match func(day time.Time) bool) string {
+		day := now
+		for !match(day) {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day.Format(`20060102`)
+	}
+	fifth := first(func(day time.Time) bool { return day.Day() == 5 })
+	workday := first(func(day time.Time) bool { return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday })
+	weekend := first(func(day time.Time) bool { return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday })
+	standUp := first(func(day time.Time) bool { return day.Weekday() == time.Monday || day.Weekday() == time.Thursday })
+
+	tbl := []struct {
+		text   string
+		title  string
+		date   string
+		repeat string
+		time   string
+	}{
+		{"Call mom tomorrow", "Call mom", tomorrow, "", ""},
+		{"Позвонить маме завтра в 18:30", "Позвонить маме", tomorrow, "", "18:30"},
+		{"Сдать отчет в пятницу", "Сдать отчет", friday, "", ""},
+		{"Submit report on Friday", "Submit report", friday, "", ""},
+		{"Оплатить интернет каждый месяц 5 числа #дом", "Оплатить интернет", fifth, "m 5", ""},
+		{"Pay rent every month on the 5th", "Pay rent", fifth, "m 5", ""},
+		{"Полить цветы каждые 3 дня", "Полить цветы", today, "d 3", ""},
+		{"Water plants every 2 weeks", "Water plants", today, "d 14", ""},
+		{"Зарядка по будням", "Зарядка", workday, "w 1,2,3,4,5", ""},
+		{"Уборка каждую субботу и воскресенье", "Уборка", weekend, "w 6,7", ""},
+		{"Stand-up every Monday and Thursday", "Stand-up", standUp, "w 1,4", ""},
+		{"Выпить воды ежедневно", "Выпить воды", today, "d 1", ""},
+		{"Buy milk today", "Buy milk", today, "", ""},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/task/quick", map[string]any{"text": v.text}, http.MethodPost)
+		assert.NoError(t, err)
+		if !assert.Empty(t, m["error"], v.text) {
+			continue
+		}
+		assert.NotEmpty(t, m["id"], v.text)
+		assert.Equal(t, v.title, m["title"], v.text)
+		assert.Equal(t, v.date, m["date"], v.text)
+		assert.Equal(t, v.repeat, m["repeat"], v.text)
+		if v.time != "" {
+			assert.Equal(t, v.time, m["time"], v.text)
+		}
+		if v.repeat != "" {
+			assert.NotEmpty(t, m["repeat_description"], v.text)
+		}
+	}
+
+	m, err := postJSON("api/task/quick", map[string]any{"text": "Оплатить интернет каждый месяц 5 числа #дом #Счета, #"},
+		http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "Оплатить интернет #", m["title"])
+	assert.Equal(t, []any{"дом", "счета"}, m["tags"])
+
+	for _, text := range []string{"", "завтра каждый день"} {
+		m, err := postJSON("api/task/quick", map[string]any{"text": text}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], text)
+	}
+}
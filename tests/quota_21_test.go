@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaRule(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "q 3 w", "20240129"},
+		{"20240129", "q 3 w", "20240205"},
+		{"20240126", "q 10 m", "20240201"},
+		{"20240126", "q 2 d", "20240127"},
+		{"20231110", "q 1 y", "20250101"},
+		{"20240126", "q 0 w", ""},
+		{"20240126", "q 101 m", ""},
+		{"20240126", "q 3", ""},
+		{"20240126", "q 3 x", ""},
+		{"20240126", "q 3 w shift", ""},
+	}
+	for _, v := range tbl {
+		body, err := getBody(fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat)))
+		assert.NoError(t, err)
+		if v.want == "" {
+			assert.NotRegexp(t, `^\d{8}$`, string(body), v.repeat)
+			continue
+		}
+		assert.Equal(t, v.want, string(body), v.repeat)
+	}
+
+	for _, v := range []struct {
+		repeat string
+		lang   string
+		want   string
+	}{
+		{"q 3 w", "ru", "3 раза в неделю"},
+		{"q 10 m", "ru", "10 раз в месяц"},
+		{"q 3 w", "en", "3 times a week"},
+		{"q 1 y", "en", "once a year"},
+	} {
+		body, err := getBody("api/describe?lang=" + v.lang + "&repeat=" + url.QueryEscape(v.repeat))
+		assert.NoError(t, err)
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.Equal(t, v.want, m["description"], v.repeat)
+	}
+}
+
+func TestQuotaDone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+	nextMonday := now.AddDate(0, 0, 7-(int(now.Weekday())+6)%7).Format(`20060102`)
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":   today,
+		"title":  "Пробежка",
+		"repeat": "q 3 w",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(m["id"])
+
+	for _, want := range []struct{ date, progress string }{
+		{today, "1"},
+		{today, "2"},
+		{nextMonday, "0"},
+	} {
+		_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		task := getTask(t, id)
+		assert.Equal(t, want.date, task["date"])
+		assert.Equal(t, want.progress, task["progress"])
+	}
+
+	// Норма прошлой недели не выполнена: счет начинается заново, задача переходит на сегодня.
+	lastWeek := now.AddDate(0, 0, -7).Format(`20060102`)
+	_, err = db.Exec("UPDATE scheduler SET date = ? WHERE id = ?", lastWeek, id)
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE completions SET date = ? WHERE task_id = ?", lastWeek, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "0", getTask(t, id)["progress"])
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	task := getTask(t, id)
+	assert.Equal(t, today, task["date"])
+	assert.Equal(t, "1", task["progress"])
+}
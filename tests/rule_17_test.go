@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Memonagi/go_final_project/pkg/date"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleStruct(t *testing.T) {
+	today := time.Now().Format(`20060102`)
+
+	tbl := []struct {
+		rule   map[string]any
+		repeat string
+	}{
+		{map[string]any{"kind": "d", "interval": 7}, "d 7"},
+		{map[string]any{"kind": "y"}, "y"},
+		{map[string]any{"kind": "w", "weekdays": []int{4, 1, 4}}, "w 1,4"},
+		{map[string]any{"kind": "m", "month_days": []int{-1, 15, 1}, "months": []int{6, 3}}, "m 1,15,-1 3,6"},
+		{map[string]any{"kind": "wm", "week_nums": []map[string]int{{"num": 2, "weekday": 2}}}, "wm 2:2"},
+		{map[string]any{"kind": "bd", "interval": 1, "shift": true}, "bd 1 shift"},
+		{map[string]any{"kind": "rrule", "rrule": "rrule:byday=mo,fr;freq=weekly"}, "FREQ=WEEKLY;BYDAY=MO,FR"},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/task", map[string]any{
+			"date":  today,
+			"title": "Структура правила",
+			"rule":  v.rule,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, m["error"], v.repeat)
+		assert.Equal(t, v.repeat, getTask(t, fmt.Sprint(m["id"]))["repeat"])
+	}
+
+	for _, v := range []map[string]any{
+		{"rule": map[string]any{"kind": "d"}},
+		{"rule": map[string]any{"kind": "d", "interval": 401}},
+		{"rule": map[string]any{"kind": "w", "weekdays": []int{8}}},
+		{"rule": map[string]any{"kind": "w", "weekdays": []int{1}, "months": []int{1}}},
+		{"rule": map[string]any{"kind": "m", "month_days": []int{0}}},
+		{"rule": map[string]any{"kind": "wm", "week_nums": []map[string]int{{"num": 6, "weekday": 1}}}},
+		{"rule": map[string]any{"kind": "rrule", "rrule": "FREQ=HOURLY"}},
+		{"rule": map[string]any{"kind": "x"}},
+		{"rule": map[string]any{"kind": "d", "interval": 7}, "repeat": "d 7"},
+	} {
+		v["date"] = today
+		v["title"] = "Структура правила"
+		m, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", v)
+	}
+}
+
+func TestRuleCanonical(t *testing.T) {
+	today := time.Now().Format(`20060102`)
+
+	for repeat, want := range map[string]string{
+		"w 5,1,3":                             "w 1,3,5",
+		"m -1,1 12,3":                         "m 1,-1 3,12",
+		"wm -1:5,1:1":                         "wm 1:1,-1:5",
+		"RRULE:INTERVAL=1;FREQ=DAILY;WKST=MO": "FREQ=DAILY",
+	} {
+		m, err := postJSON("api/task", map[string]any{
+			"date":   today,
+			"title":  "Каноническая запись",
+			"repeat": repeat,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Equal(t, want, getTask(t, fmt.Sprint(m["id"]))["repeat"], repeat)
+	}
+}
+
+func TestRuleNext(t *testing.T) {
+	after := time.Date(2024, time.January, 26, 0, 0, 0, 0, time.UTC)
+
+	for _, v := range []struct {
+		repeat string
+		want   string
+	}{
+		{"d 7", "20240202"},
+		{"w 1,4", "20240129"},
+		{"m -1", "20240131"},
+		{"y", "20250126"},
+	} {
+		rule, err := date.Parse(v.repeat)
+		if !assert.NoError(t, err, v.repeat) {
+			continue
+		}
+		next, err := rule.Next(after)
+		assert.NoError(t, err, v.repeat)
+		assert.Equal(t, v.want, next.Format(`20060102`), v.repeat)
+	}
+
+	_, err := date.Rule{Kind: "d"}.Next(after)
+	assert.Error(t, err)
+}
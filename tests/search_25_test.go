@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCase(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	addTask(t, task{
+		date:    "20240126",
+		title:   "Купить ЁЛКУ на праздник",
+		comment: "Скидка 50% по карте",
+	})
+	addTask(t, task{
+		date:    "20240126",
+		title:   "Шоколадная ёлка",
+		comment: "Для подарка",
+	})
+
+	for _, v := range []struct {
+		search string
+		want   int
+	}{
+		{"ёлк", 2},
+		{"ЁЛКУ", 1},
+		{"скидка 50%", 1},
+		{"50_", 0},
+		{"ПОДАРКА", 1},
+	} {
+		tasks := getTasks(t, url.QueryEscape(v.search))
+		assert.Equal(t, v.want, len(tasks), v.search)
+	}
+}
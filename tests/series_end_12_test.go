@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTask(t *testing.T, id string) map[string]string {
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m
+}
+
+func TestSeriesEnd(t *testing.T) {
+	now := time.Now()
+	today := now.Format(`20060102`)
+
+	for _, v := range []map[string]any{
+		{"title": "Задача", "count": "3"},
+		{"title": "Задача", "until": today},
+		{"title": "Задача", "repeat": "d 1", "count": "0"},
+		{"title": "Задача", "repeat": "d 1", "count": "abc"},
+		{"title": "Задача", "repeat": "d 1", "until": "31.12.2030"},
+		{"title": "Задача", "repeat": "d 1", "until": now.AddDate(0, 0, -1).Format(`20060102`)},
+	} {
+		m, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", v)
+	}
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":   today,
+		"title":  "Курс массажа",
+		"repeat": "d 1",
+		"count":  "2",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(m["id"])
+	assert.Equal(t, "2", getTask(t, id)["count"])
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	task := getTask(t, id)
+	assert.Equal(t, "1", task["count"])
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), task["date"])
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	notFoundTask(t, id)
+
+	m, err = postJSON("api/task", map[string]any{
+		"date":   today,
+		"title":  "Полив",
+		"repeat": "d 3",
+		"until":  now.AddDate(0, 0, 4).Format(`20060102`),
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id = fmt.Sprint(m["id"])
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 3).Format(`20060102`), getTask(t, id)["date"])
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	notFoundTask(t, id)
+}
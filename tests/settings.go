@@ -4,6 +4,6 @@ var (
 	Port         = 7540
 	DBFile       = "../scheduler.db"
 	FullNextDate = true
-	Search       = false
+	Search       = true
 	Token        = ``
 )
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubDailyDates(t *testing.T) {
+	tbl := []struct {
+		query string
+		want  []string
+	}{
+		{"now=20240126+09:30&date=20240126&time=08:00&repeat=h+4&count=3",
+			[]string{"20240126 12:00", "20240126 16:00", "20240126 20:00"}},
+		{"now=20240126+23:00&date=20240126&time=22:00&repeat=min+90&count=2",
+			[]string{"20240126 23:30", "20240127 01:00"}},
+		{"now=20240126&date=20240125&repeat=h+12&count=2", []string{"20240126 12:00", "20240127 00:00"}},
+		{"now=20240126&date=20240126&repeat=h+0", nil},
+		{"now=20240126&date=20240126&repeat=h+25", nil},
+		{"now=20240126&date=20240126&repeat=min+1441", nil},
+		{"now=20240126&date=20240126&repeat=h", nil},
+		{"now=20240126&date=20240126&repeat=" + url.QueryEscape("h 2 shift"), nil},
+		{"now=20240126&date=20240126&time=8&repeat=h+2", nil},
+	}
+	for _, v := range tbl {
+		body, err := getBody("api/nextdates?" + v.query)
+		assert.NoError(t, err)
+
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+
+		if v.want == nil {
+			assert.NotEmpty(t, m["error"], v.query)
+			continue
+		}
+
+		var dates map[string][]string
+		assert.NoError(t, json.Unmarshal(body, &dates))
+		assert.Equal(t, v.want, dates["dates"], v.query)
+	}
+
+	for repeat, want := range map[string]string{
+		"h 1":    "каждый час",
+		"h 4":    "каждые 4 часа",
+		"min 30": "каждые 30 минут",
+	} {
+		body, err := getBody("api/describe?repeat=" + url.QueryEscape(repeat))
+		assert.NoError(t, err)
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.Equal(t, want, m["description"], repeat)
+	}
+}
+
+func TestSubDailyDone(t *testing.T) {
+	now := time.Now()
+	today := now.Format(`20060102`)
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":   today,
+		"time":   "00:00",
+		"title":  "Принять лекарство",
+		"repeat": "h 4",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(m["id"])
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(time.Duration(now.Hour()/4+1) * 4 * time.Hour)
+	task := getTask(t, id)
+	assert.Equal(t, next.Format(`20060102`), task["date"])
+	assert.Equal(t, next.Format(`15:04`), task["time"])
+
+	m, err = postJSON("api/task", map[string]any{
+		"date":   today,
+		"title":  "Проверить датчики",
+		"repeat": "min 30",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, getTask(t, fmt.Sprint(m["id"]))["time"])
+}
+
+func TestTasksOrderByTime(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+
+	for _, v := range []string{"18:00", "", "09:00", "09:30"} {
+		_, err := postJSON("api/task", map[string]any{
+			"date":  date,
+			"time":  v,
+			"title": "Задача на " + v,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+	}
+
+	var times []string
+	for _, task := range getTasks(t, "") {
+		times = append(times, task["time"])
+	}
+	assert.Equal(t, []string{"", "09:00", "09:30", "18:00"}, times)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTags(t *testing.T) map[string]int {
+	body, err := requestJSON("api/tags", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &m))
+
+	tags := make(map[string]int)
+	for _, tag := range m["tags"] {
+		tags[tag.Name] = tag.Count
+	}
+	return tags
+}
+
+func taskTags(t *testing.T, id string) []any {
+	m, err := postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	tags, _ := m["tags"].([]any)
+	return tags
+}
+
+func filterTasks(t *testing.T, query string) []string {
+	body, err := requestJSON("api/tasks?search=20.04.2031&"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+
+	var titles []string
+	for _, task := range m["tasks"] {
+		titles = append(titles, task["title"].(string))
+	}
+	return titles
+}
+
+func TestTags(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	const date = "20310420"
+
+	ids := make(map[string]string)
+	// Задачи с метками удаляются после теста, чтобы не попасть в списки задач других тестов.
+	defer func() {
+		for _, id := range ids {
+			_, err := db.Exec("DELETE FROM scheduler WHERE id = ?", id)
+			assert.NoError(t, err)
+		}
+	}()
+
+	for _, v := range []struct {
+		title string
+		tags  []string
+	}{
+		{"Отчет", []string{"Work", "#urgent", "work"}},
+		{"Спортзал", []string{"personal"}},
+		{"Обед с коллегами", []string{"work", "personal"}},
+		{"Без меток", nil},
+	} {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": v.title, "tags": v.tags}, http.MethodPost)
+		assert.NoError(t, err)
+		if !assert.Empty(t, ret["error"], v.title) {
+			return
+		}
+		ids[v.title] = ret["id"].(string)
+	}
+	assert.Equal(t, []any{"urgent", "work"}, taskTags(t, ids["Отчет"]))
+	assert.Empty(t, taskTags(t, ids["Без меток"]))
+
+	for _, v := range []struct {
+		query string
+		want  []string
+	}{
+		{"tag=work", []string{"Отчет", "Обед с коллегами"}},
+		{"tag=WORK&tag=-personal", []string{"Отчет"}},
+		{"tag=-work", []string{"Спортзал", "Без меток"}},
+		{"tag=work&tag=urgent", []string{"Отчет"}},
+		{"tag=home", nil},
+	} {
+		assert.ElementsMatch(t, v.want, filterTasks(t, v.query), v.query)
+	}
+
+	assert.Equal(t, map[string]int{"work": 2, "personal": 2, "urgent": 1}, getTags(t))
+
+	ret, err := postJSON("api/task", map[string]any{
+		"id":    ids["Отчет"],
+		"date":  date,
+		"title": "Отчет",
+		"tags":  []string{"home"},
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	assert.Equal(t, []any{"home"}, taskTags(t, ids["Отчет"]))
+
+	_, err = postJSON("api/task?id="+ids["Обед с коллегами"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"personal": 1, "home": 1}, getTags(t))
+
+	for _, v := range []string{"-work", "two words", "", "a,b", strings.Repeat("x", 33)} {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": "Ошибка", "tags": []string{v}}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], v)
+	}
+
+	for _, v := range []string{"a,b", "-", "--work"} {
+		body, err := requestJSON("api/tasks?tag="+v, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.NotEmpty(t, m["error"], v)
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateTimezone(t *testing.T) {
+	tbl := []struct {
+		now  string
+		tz   string
+		date string
+		rule string
+		want string
+	}{
+		{"20240330", "Europe/Berlin", "20240329", "d 1", "20240331"},
+		{"20240330", "Europe/Berlin", "20240329", "FREQ=DAILY", "20240331"},
+		{"20240330", "Europe/Berlin", "20240301", "FREQ=WEEKLY;BYDAY=SU", "20240331"},
+		{"20241102", "America/New_York", "20241027", "w 7", "20241103"},
+		{"20241102", "America/New_York", "20241001", "m -1", "20241130"},
+		{"20241102", "Unknown/Zone", "20241001", "m -1", ""},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=%s&tz=%s&date=%s&repeat=%s",
+			v.now, url.QueryEscape(v.tz), v.date, url.QueryEscape(v.rule))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q, %q}`, v.now, v.tz, v.date, v.rule)
+	}
+}
+
+func TestTaskTimezone(t *testing.T) {
+	for _, v := range []map[string]any{
+		{"title": "Созвон", "time": "25:00"},
+		{"title": "Созвон", "time": "9 утра"},
+		{"title": "Созвон", "timezone": "Europe/Nowhere"},
+	} {
+		m, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", v)
+	}
+
+	for _, tz := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		loc, err := time.LoadLocation(tz)
+		assert.NoError(t, err)
+
+		m, err := postJSON("api/task", map[string]any{
+			"title":    "Созвон",
+			"time":     "09:30",
+			"timezone": tz,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		id := fmt.Sprint(m["id"])
+
+		body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var task map[string]string
+		assert.NoError(t, json.Unmarshal(body, &task))
+		assert.Equal(t, time.Now().In(loc).Format(`20060102`), task["date"])
+		assert.Equal(t, "09:30", task["time"])
+		assert.Equal(t, tz, task["timezone"])
+	}
+}
+
+func TestAddTaskKeepsDate(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	for _, v := range []struct {
+		date string
+		want string
+	}{
+		{day(0), day(0)},
+		{day(3), day(3)},
+		{day(-3), day(4)},
+	} {
+		id := addTask(t, task{
+			date:   v.date,
+			title:  "Поплавать",
+			repeat: "d 7",
+		})
+		assert.Equal(t, v.want, getTask(t, id)["date"], v.date)
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTrash(t *testing.T) map[string]map[string]string {
+	body, err := requestJSON("api/trash", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+
+	trash := make(map[string]map[string]string)
+	for _, task := range m["tasks"] {
+		trash[task["id"]] = task
+	}
+	return trash
+}
+
+func TestTrash(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	today := time.Now().Format(`20060102`)
+
+	id := addTask(t, task{
+		date:   today,
+		title:  "Разобрать почту",
+		repeat: "d 1",
+	})
+	assert.NotContains(t, getTrash(t), id)
+
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+
+	var rows int
+	assert.NoError(t, db.Get(&rows, `SELECT count(id) FROM scheduler WHERE id = ?`, id))
+	assert.Equal(t, 1, rows)
+
+	for _, task := range getTasks(t, "") {
+		assert.NotEqual(t, id, task["id"])
+	}
+
+	trashed, ok := getTrash(t)[id]
+	if assert.True(t, ok) {
+		assert.Equal(t, "Разобрать почту", trashed["title"])
+		assert.Equal(t, "deleted", trashed["delete_reason"])
+		_, err = time.Parse(time.RFC3339, trashed["deleted_at"])
+		assert.NoError(t, err)
+	}
+
+	for _, v := range []struct {
+		path   string
+		values map[string]any
+		method string
+	}{
+		{"api/task?id=" + id, nil, http.MethodDelete},
+		{"api/task/done?id=" + id, nil, http.MethodPost},
+		{"api/task", map[string]any{"id": id, "date": today, "title": "Разобрать почту", "repeat": "d 1"}, http.MethodPut},
+		{"api/task/exceptions?id=" + id + "&date=" + today, nil, http.MethodPost},
+	} {
+		m, err := postJSON(v.path, v.values, v.method)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], v.path)
+	}
+
+	ret, err = postJSON("api/task/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, "Разобрать почту", getTask(t, id)["title"])
+	assert.NotContains(t, getTrash(t), id)
+
+	for _, v := range []string{id, "", "abc"} {
+		m, err := postJSON("api/task/restore?id="+v, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], v)
+	}
+
+	id = addTask(t, task{
+		date:  today,
+		title: "Отправить письмо",
+	})
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	notFoundTask(t, id)
+	assert.Equal(t, "done", getTrash(t)[id]["delete_reason"])
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRule(t *testing.T) {
+	m, err := postJSON("api/rules/validate", map[string]any{"repeat": "w 4,1"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, true, m["valid"])
+	assert.Equal(t, "w 1,4", m["repeat"])
+	assert.Equal(t, "по понедельникам и четвергам", m["description"])
+	assert.Nil(t, m["diagnostic"])
+
+	tbl := []struct {
+		repeat   string
+		code     string
+		token    string
+		position float64
+	}{
+		{"", "empty", "", 0},
+		{"x 1", "unknown_kind", "x", 0},
+		{"D 1", "unknown_kind", "D", 0},
+		{"d", "wrong_arguments", "", 1},
+		{"d 1 2", "wrong_arguments", "2", 4},
+		{"d 500", "invalid_days", "500", 2},
+		{"w 1,8,3", "invalid_days", "8", 4},
+		{"m 1,15 1,13", "invalid_rule", "13", 9},
+		{"wm 2:2,6:1", "invalid_days", "6:1", 7},
+		{"y 03-08,02-30", "invalid_date", "02-30", 8},
+		{"y 03-08 bad", "invalid_rule", "bad", 8},
+		{"q 3 x", "invalid_rule", "x", 4},
+		{"cron 0 25 * * *", "invalid_rule", "25", 7},
+		{"cron 0 9 * *", "wrong_arguments", "", 12},
+		{"cron 0 0\n0 0 0 0", "wrong_arguments", "0", 15},
+		{"cron 0\t25 * * *", "invalid_rule", "25", 7},
+		{"cron 0 9\t* *", "wrong_arguments", "", 12},
+		{"h 4 shift", "invalid_rule", "shift", 4},
+		{"q 3 w shift", "invalid_rule", "shift", 6},
+		{"FREQ=WEEKLY;BYDAY=MO,XX", "invalid_days", "BYDAY=MO,XX", 12},
+		{"RRULE:FREQ=DAILY;COUNT=0", "invalid_rule", "COUNT=0", 17},
+		{"FREQ=DAILY;FOO=1", "invalid_rule", "FOO=1", 11},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20250101", "invalid_rule", "FREQ=DAILY;COUNT=2;UNTIL=20250101", 0},
+		{"m 30 2", "invalid_days", "m 30 2", 0},
+		{"cron 0 9 31 2 *", "invalid_days", "cron 0 9 31 2 *", 0},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "invalid_days", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", 0},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/rules/validate", map[string]any{"repeat": v.repeat}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Equal(t, false, m["valid"], v.repeat)
+
+		diagnostic, ok := m["diagnostic"].(map[string]any)
+		if !assert.True(t, ok, "Ожидается описание ошибки для правила %q", v.repeat) {
+			continue
+		}
+		assert.Equal(t, v.code, diagnostic["code"], v.repeat)
+		assert.Equal(t, v.token, diagnostic["token"], v.repeat)
+		assert.Equal(t, v.position, diagnostic["position"], v.repeat)
+		assert.NotEmpty(t, diagnostic["message"], v.repeat)
+		assert.NotEmpty(t, diagnostic["suggestion"], v.repeat)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYearDates(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "y 03-08,09-01,12-31", "20240308"},
+		{"20240310", "y 03-08,09-01,12-31", "20240901"},
+		{"20241231", "y 12-31,03-08,09-01", "20250308"},
+		{"20240126", "y 02-29 feb28", "20240229"},
+		{"20240301", "y 02-29", "20250301"},
+		{"20240301", "y 02-29 mar1", "20250301"},
+		{"20240301", "y 02-29 feb28", "20250228"},
+		{"20240301", "y 02-29 skip", "20280229"},
+		{"20240126", "y 01-01 shift", "20250101"},
+		{"20240126", "y 02-30", ""},
+		{"20240126", "y 13-01", ""},
+		{"20240126", "y 3-8", ""},
+		{"20240126", "y 03-08 bad", ""},
+		{"20240126", "y feb28", "20250126"},
+		{"20240229", "y feb28", "20250228"},
+		{"20240229", "y mar1", "20250301"},
+		{"20240229", "y skip", "20280229"},
+		{"20240229", "y", "20250301"},
+		{"20240126", "y bad", ""},
+		{"20240126", "y feb28 skip", ""},
+		{"20240126", "y 03-08 skip 1", ""},
+	}
+	for _, v := range tbl {
+		body, err := getBody(fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat)))
+		assert.NoError(t, err)
+		if v.want == "" {
+			assert.NotRegexp(t, `^\d{8}$`, string(body), v.repeat)
+			continue
+		}
+		assert.Equal(t, v.want, string(body), v.repeat)
+	}
+
+	for _, v := range []struct {
+		repeat string
+		lang   string
+		want   string
+	}{
+		{"y 09-01,03-08,12-31", "ru", "каждый год 8 марта, 1 сентября и 31 декабря"},
+		{"y 09-01,03-08,12-31", "en", "every year on March 8, September 1 and December 31"},
+		{"y 02-29 feb28", "ru", "каждый год 29 февраля (28 февраля в невисокосные годы)"},
+		{"y 02-29 skip", "en", "every year on February 29 (February 29 only in leap years)"},
+		{"y feb28", "ru", "каждый год (28 февраля в невисокосные годы)"},
+		{"y mar1", "en", "every year"},
+	} {
+		body, err := getBody("api/describe?lang=" + v.lang + "&repeat=" + url.QueryEscape(v.repeat))
+		assert.NoError(t, err)
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.Equal(t, v.want, m["description"], v.repeat)
+	}
+}
+
+func TestYearDatesTask(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:   "20240126",
+		title:  "Праздники",
+		repeat: "y 12-31,03-08,03-08 mar1",
+	})
+
+	m := getTask(t, id)
+	assert.Equal(t, "y 03-08,12-31", m["repeat"])
+
+	// Правило «y feb28» без дат относится к 29 февраля самой задачи и возвращает ее на 29 февраля.
+	leap := time.Now().Year() + 1
+	for leap%4 != 0 || leap%100 == 0 && leap%400 != 0 {
+		leap++
+	}
+
+	id = addTask(t, task{
+		date:   fmt.Sprintf("%d0229", leap),
+		title:  "День рождения",
+		repeat: "y feb28",
+	})
+
+	for i, want := range []string{"0228", "0228", "0228", "0229"} {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		m = getTask(t, id)
+		assert.Equal(t, fmt.Sprintf("%d%s", leap+i+1, want), m["date"])
+		assert.Equal(t, "y 02-29 feb28", m["repeat"])
+	}
+}